@@ -90,6 +90,27 @@ func TagURL(v string) TagOption {
 	}
 }
 
+// TagExtension sets a vendor extension, x-*, on the tag
+func TagExtension(name string, value interface{}) TagOption {
+	swagger.ValidateExtension(name)
+	return func(t *swagger.Tag) {
+		if t.Extensions == nil {
+			t.Extensions = map[string]interface{}{}
+		}
+		t.Extensions[name] = value
+	}
+}
+
+// TagInternal hides the tag, and every endpoint tagged with it, from public views of the api; see swagger.API.Filter
+func TagInternal() TagOption {
+	return TagExtension(swagger.InternalExtension, true)
+}
+
+// TagAudiences restricts the tag, and endpoints tagged with it, to views of the api for the specified audiences
+func TagAudiences(audiences ...string) TagOption {
+	return TagExtension(swagger.AudiencesExtension, audiences)
+}
+
 // Tag adds a tag to the swagger api
 func Tag(name, description string, options ...TagOption) Option {
 	return func(builder *Builder) {
@@ -158,6 +179,73 @@ func Security(scheme string, scopes ...string) Option {
 	}
 }
 
+// DefaultError declares an RFC 7807 problem response as the default response of every endpoint in the API
+func DefaultError(description string) Option {
+	return func(builder *Builder) {
+		r := swagger.ProblemResponse(description)
+		builder.API.DefaultResponse = &r
+
+		// apply to endpoints that were added before this option
+		for _, endpoints := range builder.API.Paths {
+			endpoints.Walk(builder.API.AddEndpoint)
+		}
+	}
+}
+
+// Envelope wraps the body of every successful response in the struct prototype, whose interface{} field named field in
+// json holds the payload e.g. Envelope(APIResponse{}, "data"); see swagger.NewEnvelope
+func Envelope(prototype interface{}, field string) Option {
+	envelope := swagger.NewEnvelope(prototype, field)
+	return func(builder *Builder) {
+		builder.API.Envelope = envelope
+
+		// apply to endpoints that were added before this option
+		for _, endpoints := range builder.API.Paths {
+			endpoints.Walk(builder.API.AddEndpoint)
+		}
+	}
+}
+
+// Translations adds translated descriptions for the locale to the API's catalog; see swagger.Catalog for the message
+// keys
+func Translations(locale string, messages map[string]string) Option {
+	return func(builder *Builder) {
+		if builder.API.Catalog == nil {
+			builder.API.Catalog = swagger.Catalog{}
+		}
+
+		if builder.API.Catalog[locale] == nil {
+			builder.API.Catalog[locale] = map[string]string{}
+		}
+
+		for k, v := range messages {
+			builder.API.Catalog[locale][k] = v
+		}
+	}
+}
+
+// Extension sets a vendor extension, x-*, on the api
+func Extension(name string, value interface{}) Option {
+	swagger.ValidateExtension(name)
+	return func(builder *Builder) {
+		if builder.API.Extensions == nil {
+			builder.API.Extensions = map[string]interface{}{}
+		}
+		builder.API.Extensions[name] = value
+	}
+}
+
+// InfoExtension sets a vendor extension, x-*, on info
+func InfoExtension(name string, value interface{}) Option {
+	swagger.ValidateExtension(name)
+	return func(builder *Builder) {
+		if builder.API.Info.Extensions == nil {
+			builder.API.Info.Extensions = map[string]interface{}{}
+		}
+		builder.API.Info.Extensions[name] = value
+	}
+}
+
 // New constructs a new api builder
 func New(options ...Option) *swagger.API {
 	b := &Builder{
@@ -2,7 +2,10 @@ package docs
 
 import (
 	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+	"github.com/threeq/docs/swagger/versioning"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -108,3 +111,250 @@ func TestSecurity(t *testing.T) {
 	assert.Len(t, api.Security.Requirements, 1)
 	assert.Contains(t, api.Security.Requirements[0], "basic")
 }
+
+func TestDefaultError(t *testing.T) {
+	api := New(
+		Endpoints(endpoint.Get("/pets", "list pets")),
+		DefaultError("unexpected error"),
+		Endpoints(endpoint.Get("/owners", "list owners", endpoint.StandardErrors())),
+	)
+
+	pets := api.Paths["/pets"].Get
+	assert.Equal(t, "unexpected error", pets.Responses["default"].Description)
+
+	owners := api.Paths["/owners"].Get
+	assert.Equal(t, "unexpected error", owners.Responses["default"].Description)
+	assert.Len(t, owners.Responses, len(swagger.StandardErrorCodes)+1)
+	assert.Equal(t, "Not Found", owners.Responses["404"].Description)
+	assert.Equal(t, "#/definitions/swaggerProblemDetails", owners.Responses["422"].Schema.Ref)
+}
+
+func TestBodyExample(t *testing.T) {
+	type Pet struct {
+		Name string `json:"name"`
+	}
+
+	e := endpoint.Post("/pets", "create pet",
+		endpoint.Body(Pet{}, "the pet", true),
+		endpoint.BodyExample("application/json", Pet{Name: "fido"}),
+		endpoint.BodyExample("application/xml", "<pet><name>fido</name></pet>"),
+	)
+	body := e.Parameters[0]
+	assert.Len(t, body.Examples, 2)
+	assert.Equal(t, Pet{Name: "fido"}, body.Schema.Example)
+
+	assert.Panics(t, func() {
+		endpoint.Get("/pets", "list pets", endpoint.BodyExample("application/json", Pet{}))
+	})
+}
+
+func TestTranslations(t *testing.T) {
+	api := New(
+		Title("Pets"),
+		Translations("zh", map[string]string{"info.title": "宠物"}),
+		Translations("zh", map[string]string{"info.description": "管理宠物"}),
+	)
+	assert.Len(t, api.Catalog["zh"], 2)
+	assert.Equal(t, "宠物", api.Localize("zh-CN").Info.Title)
+	assert.Equal(t, "Pets", api.Info.Title)
+}
+
+func TestExtension(t *testing.T) {
+	api := New(
+		Extension("x-gateway", "pets"),
+		InfoExtension("x-logo", "logo.png"),
+		Tag("pets", "pet operations", TagExtension("x-internal", true)),
+		Endpoints(endpoint.Get("/pets/{id}", "find pet",
+			endpoint.Path("id", "integer", "pet id", true),
+			endpoint.Extension("x-rate-limit", 100),
+			endpoint.ParameterExtension("id", "x-go-name", "PetID"),
+			endpoint.Response(200, "", "", "the pet", endpoint.ResponseExtension("x-cache", "1h")),
+		)),
+	)
+
+	assert.Equal(t, "pets", api.Extensions["x-gateway"])
+	assert.Equal(t, "logo.png", api.Info.Extensions["x-logo"])
+	assert.Equal(t, true, api.Tags[0].Extensions["x-internal"])
+
+	e := api.Paths["/pets/{id}"].Get
+	assert.Equal(t, 100, e.Extensions["x-rate-limit"])
+	assert.Equal(t, "PetID", e.Parameters[0].Extensions["x-go-name"])
+	assert.Equal(t, "1h", e.Responses["200"].Extensions["x-cache"])
+
+	assert.Panics(t, func() { Extension("gateway", "pets") })
+	assert.Panics(t, func() {
+		endpoint.Get("/pets", "list pets", endpoint.ParameterExtension("id", "x-go-name", "PetID"))
+	})
+}
+
+func TestDeprecated(t *testing.T) {
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	api := New(
+		Endpoints(endpoint.Get("/pets", "list pets",
+			endpoint.Query("sort", "string", "sort order", false),
+			endpoint.Deprecated(sunset, "/v2/pets"),
+			endpoint.DeprecatedParameter("sort"),
+		)),
+	)
+
+	e := api.Paths["/pets"].Get
+	assert.True(t, e.Deprecated)
+	assert.Equal(t, sunset, e.Sunset)
+	assert.Equal(t, "/v2/pets", e.Successor)
+	assert.Equal(t, "2030-01-01T00:00:00Z", e.Extensions["x-sunset"])
+	assert.Equal(t, "/v2/pets", e.Extensions["x-successor"])
+	assert.True(t, e.Parameters[0].Deprecated)
+
+	since := time.Date(2023, time.June, 30, 0, 0, 0, 0, time.UTC)
+	e = endpoint.Get("/pets", "list pets", endpoint.DeprecatedSince(since))
+	assert.True(t, e.Deprecated)
+	assert.Equal(t, since, e.DeprecatedSince)
+	assert.Equal(t, "2023-06-30T00:00:00Z", e.Extensions["x-deprecated-since"])
+
+	assert.Panics(t, func() {
+		endpoint.Get("/pets", "list pets", endpoint.DeprecatedParameter("sort"))
+	})
+}
+
+func TestParameterConstraints(t *testing.T) {
+	api := New(
+		Endpoints(endpoint.Get("/pets", "list pets",
+			endpoint.Query("limit", "integer", "maximum number of pets", false),
+			endpoint.Minimum("limit", 1),
+			endpoint.Maximum("limit", 100),
+			endpoint.Query("name", "string", "name filter", false),
+			endpoint.MinLength("name", 1),
+			endpoint.MaxLength("name", 64),
+			endpoint.Query("status", "string", "status filter", false),
+			endpoint.Enum("status", "available", "sold"),
+		)),
+	)
+
+	params := api.Paths["/pets"].Get.Parameters
+	assert.Equal(t, 1.0, *params[0].Minimum)
+	assert.Equal(t, 100.0, *params[0].Maximum)
+	assert.Equal(t, 1, *params[1].MinLength)
+	assert.Equal(t, 64, *params[1].MaxLength)
+	assert.Equal(t, []string{"available", "sold"}, params[2].Enum)
+
+	assert.Panics(t, func() {
+		endpoint.Get("/pets", "list pets", endpoint.Minimum("limit", 1))
+	})
+}
+
+func TestVisibility(t *testing.T) {
+	api := New(
+		Tag("admin", "administration", TagInternal()),
+		Tag("billing", "invoices", TagAudiences("partners")),
+		Endpoints(
+			endpoint.Get("/pets", "list pets",
+				endpoint.Query("debug", "boolean", "include diagnostics", false),
+				endpoint.InternalParameter("debug"),
+			),
+			endpoint.Delete("/pets", "purge pets", endpoint.Internal()),
+			endpoint.Get("/invoices", "list invoices", endpoint.Tags("billing")),
+			endpoint.Get("/reports", "list reports", endpoint.Tags("admin")),
+			endpoint.Get("/stats", "usage statistics", endpoint.Audiences("partners", "staff")),
+		),
+	)
+
+	public := api.Filter(swagger.Public)
+	assert.Empty(t, public.Paths["/pets"].Get.Parameters)
+	assert.Nil(t, public.Paths["/pets"].Delete)
+	assert.NotContains(t, public.Paths, "/reports")
+	assert.Contains(t, public.Paths, "/invoices")
+	assert.Len(t, public.Tags, 1)
+
+	staff := api.Filter(swagger.ForAudience("staff"))
+	assert.NotContains(t, staff.Paths, "/invoices")
+	assert.Contains(t, staff.Paths, "/stats")
+
+	assert.Panics(t, func() {
+		endpoint.Get("/pets", "list pets", endpoint.InternalParameter("debug"))
+	})
+}
+
+func TestSinceUntil(t *testing.T) {
+	api := New(
+		Endpoints(
+			endpoint.Get("/pets", "list pets", endpoint.Until("v1")),
+			endpoint.Get("/animals", "list animals", endpoint.Since("v2")),
+			endpoint.Get("/stores", "list stores"),
+		),
+	)
+
+	r := versioning.New(api, versioning.URLPrefix(), "v1", "v2")
+	assert.Contains(t, r.API("v1").Paths, "/pets")
+	assert.NotContains(t, r.API("v1").Paths, "/animals")
+	assert.Contains(t, r.API("v2").Paths, "/animals")
+	assert.NotContains(t, r.API("v2").Paths, "/pets")
+	assert.Contains(t, r.API("v2").Paths, "/stores")
+}
+
+type envelope struct {
+	Code int         `json:"code"`
+	Data interface{} `json:"data"`
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestEnvelope(t *testing.T) {
+	api := New(
+		Endpoints(endpoint.Get("/users", "list users",
+			endpoint.Response(200, []user{}, "", "the users"),
+		)),
+		Envelope(envelope{}, "data"),
+		Endpoints(endpoint.Get("/health", "health check",
+			endpoint.Response(200, "", "", "ok"),
+			endpoint.NoEnvelope(),
+		)),
+	)
+
+	assert.Equal(t, "#/definitions/docsuserListResponse", api.Paths["/users"].Get.Responses["200"].Schema.Ref)
+	assert.Equal(t, "#/definitions/string", api.Paths["/health"].Get.Responses["200"].Schema.Ref)
+	assert.Contains(t, api.Definitions, "docsuser")
+	assert.Panics(t, func() { Envelope(envelope{}, "code") })
+}
+
+func TestPaginated(t *testing.T) {
+	api := New(
+		Envelope(envelope{}, "data"),
+		Endpoints(endpoint.Get("/users", "list users",
+			endpoint.Paginated(swagger.PagePagination, user{}),
+		)),
+	)
+
+	e := api.Paths["/users"].Get
+	assert.Len(t, e.Parameters, 2)
+	assert.Equal(t, "page", e.Parameters[0].Name)
+	assert.Equal(t, "page_size", e.Parameters[1].Name)
+
+	response := e.Responses["200"]
+	assert.Equal(t, "#/definitions/docsuserListResponse", response.Schema.Ref)
+	assert.Contains(t, response.Headers, "X-Total-Count")
+	assert.Contains(t, response.Headers, "Link")
+
+	cursor := endpoint.Get("/users", "list users", endpoint.Paginated(swagger.CursorPagination, &user{}))
+	assert.Equal(t, "cursor", cursor.Parameters[0].Name)
+	assert.Equal(t, "array", cursor.Responses["200"].Schema.Type)
+	assert.NotContains(t, cursor.Responses["200"].Headers, "X-Total-Count")
+
+	assert.Panics(t, func() { endpoint.Paginated("seek", user{}) })
+}
+
+func TestResponseMediaTypes(t *testing.T) {
+	api := New(
+		Endpoints(endpoint.Get("/users/export", "export users",
+			endpoint.Response(200, "", "", "users as csv", endpoint.MediaTypes("text/csv")),
+			endpoint.File(202, "users as a spreadsheet", "application/vnd.ms-excel"),
+			endpoint.StandardErrors(),
+		)),
+	)
+
+	e := api.Paths["/users/export"].Get
+	assert.Equal(t, []string{"application/json", "text/csv", "application/vnd.ms-excel", "application/problem+json"}, e.Produces)
+	assert.Equal(t, "file", e.Responses["202"].Schema.Type)
+	assert.Equal(t, []string{"application/octet-stream"}, endpoint.Get("/", "", endpoint.File(200, "")).Responses["200"].MediaTypes)
+}
@@ -0,0 +1,155 @@
+// Command docs-gen generates client code from a swagger json document e.g. as served by swagger.API.Handler
+//
+//	docs-gen -lang go -package petstore -o petstore/client.go swagger.json
+//	docs-gen -lang ts -o src/api.ts swagger.json
+//
+// The postman and insomnia languages export a collection for those api clients instead of client code
+//
+//	docs-gen -lang postman -o petstore.postman.json swagger.json
+//
+// The descriptions subcommand extracts doc comments from the go package in each directory and writes a file that
+// registers them with swagger.RegisterDescriptions; typically invoked with go generate from the models package
+//
+//	//go:generate docs-gen descriptions -o descriptions.go .
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/codegen"
+	"github.com/threeq/docs/swagger/export"
+	"github.com/threeq/docs/swagger/godoc"
+)
+
+var generators = map[string]func(api *swagger.API, options ...codegen.Option) ([]byte, error){
+	"go": codegen.Go,
+	"ts": func(api *swagger.API, _ ...codegen.Option) ([]byte, error) {
+		return codegen.TypeScript(api)
+	},
+	"postman": func(api *swagger.API, _ ...codegen.Option) ([]byte, error) {
+		return export.Postman(api)
+	},
+	"insomnia": func(api *swagger.API, _ ...codegen.Option) ([]byte, error) {
+		return export.Insomnia(api)
+	},
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "descriptions" {
+		descriptions(os.Args[2:])
+		return
+	}
+
+	var (
+		lang   = flag.String("lang", "go", "language to generate: go, ts, postman or insomnia")
+		pkg    = flag.String("package", "client", "package name of the generated go client")
+		output = flag.String("o", "", "output file; defaults to stdout")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: docs-gen [flags] <swagger.json | ->\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *lang, *output, codegen.Package(*pkg)); err != nil {
+		fmt.Fprintf(os.Stderr, "docs-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func descriptions(args []string) {
+	fs := flag.NewFlagSet("descriptions", flag.ExitOnError)
+	var (
+		pkg    = fs.String("package", "", "package name of the generated file; defaults to $GOPACKAGE")
+		output = fs.String("o", "", "output file; defaults to stdout")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: docs-gen descriptions [flags] <dir>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := runDescriptions(fs.Args(), *pkg, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "docs-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runDescriptions(dirs []string, pkg, output string) error {
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE") // set by go generate
+	}
+	if pkg == "" {
+		return fmt.Errorf("-package is required outside of go generate")
+	}
+
+	table := map[string]string{}
+	for _, dir := range dirs {
+		v, err := godoc.Parse(dir)
+		if err != nil {
+			return err
+		}
+		for k, text := range v {
+			table[k] = text
+		}
+	}
+
+	src, err := godoc.Generate(pkg, table)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
+
+func run(input, lang, output string, options ...codegen.Option) error {
+	generate, ok := generators[lang]
+	if !ok {
+		return fmt.Errorf("unsupported language, %v", lang)
+	}
+
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	api, err := swagger.Load(r)
+	if err != nil {
+		return fmt.Errorf("unable to load %v: %v", input, err)
+	}
+
+	src, err := generate(api, options...)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
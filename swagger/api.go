@@ -1,25 +1,37 @@
 package swagger
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"path"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 // Object represents the object entity from the swagger definition
 type Object struct {
-	IsArray    bool                `json:"-"`
-	GoType     reflect.Type        `json:"-"`
-	Name       string              `json:"-"`
-	Type       string              `json:"type"`
-	Format     string              `json:"format,omitempty"`
-	Required   []string            `json:"required,omitempty"`
-	Properties map[string]Property `json:"properties,omitempty"`
+	IsArray     bool                `json:"-"`
+	GoType      reflect.Type        `json:"-"`
+	Name        string              `json:"-"`
+	Ref         string              `json:"$ref,omitempty"`
+	Type        string              `json:"type,omitempty"`
+	Format      string              `json:"format,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Required    []string            `json:"required,omitempty"`
+	Properties  map[string]Property `json:"properties,omitempty"`
+
+	// Discriminator names the property that selects between the implementations of a polymorphic definition and AllOf
+	// composes an implementation from its base definition and its own properties; see RegisterPolymorphic
+	Discriminator string   `json:"discriminator,omitempty"`
+	AllOf         []Object `json:"allOf,omitempty"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // Property represents the property entity from the swagger definition
@@ -30,8 +42,25 @@ type Property struct {
 	Enum        []string     `json:"enum,omitempty"`
 	Format      string       `json:"format,omitempty"`
 	Ref         string       `json:"$ref,omitempty"`
-	Example     string       `json:"example,omitempty"`
+	Example     interface{}  `json:"example,omitempty"`
 	Items       *Items       `json:"items,omitempty"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
+
+	// AdditionalProperties describes the values of a map
+	AdditionalProperties *Items `json:"additionalProperties,omitempty"`
+
+	// Minimum and Maximum bound numeric values; MinLength and MaxLength bound the length of strings.  Set from the
+	// minimum, maximum, minLength and maxLength struct tags
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+
+	// Descriptions holds per-locale descriptions from desc_{locale} struct tags e.g. desc_zh
+	Descriptions map[string]string `json:"-"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // Contact represents the contact entity from the swagger definition; used by Info
@@ -53,6 +82,9 @@ type Info struct {
 	Title          string  `json:"title,omitempty"`
 	Contact        Contact `json:"contact"`
 	License        License `json:"license"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // SecurityScheme represents a security scheme from the swagger definition.
@@ -65,6 +97,21 @@ type SecurityScheme struct {
 	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
 	TokenURL         string            `json:"tokenUrl,omitempty"`
 	Scopes           map[string]string `json:"scopes,omitempty"`
+
+	// Scheme and BearerFormat describe an OpenAPI 3 http scheme e.g. bearer; OpenIDConnectURL the discovery url of an
+	// openIdConnect scheme; Flows the flows of a multi-flow oauth2 scheme keyed by OpenAPI 3 flow name.  Swagger 2.0
+	// can't express these, so they are degraded when marshaled; see Warnings
+	Scheme           string               `json:"-"`
+	BearerFormat     string               `json:"-"`
+	OpenIDConnectURL string               `json:"-"`
+	Flows            map[string]OAuthFlow `json:"-"`
+}
+
+// OAuthFlow describes one flow of an oauth2 security scheme; the scopes are shared with the scheme
+type OAuthFlow struct {
+	AuthorizationURL string `json:"authorizationUrl,omitempty"`
+	TokenURL         string `json:"tokenUrl,omitempty"`
+	RefreshURL       string `json:"refreshUrl,omitempty"`
 }
 
 // SecuritySchemeOption provides additional customizations to the SecurityScheme.
@@ -85,11 +132,11 @@ func BasicSecurity() SecuritySchemeOption {
 }
 
 // APIKeySecurity defines a security scheme for API key authentication. "in" is
-// the location of the API key (query or header). "name" is the name of the
-// header or query parameter to be used.
+// the location of the API key (query, header or cookie). "name" is the name of the
+// header, query parameter or cookie to be used.
 func APIKeySecurity(name, in string) SecuritySchemeOption {
-	if in != "header" && in != "query" {
-		panic(fmt.Errorf(`APIKeySecurity "in" parameter must be one of: "header" or "query"`))
+	if in != "header" && in != "query" && in != "cookie" {
+		panic(fmt.Errorf(`APIKeySecurity "in" parameter must be one of: "header", "query" or "cookie"`))
 	}
 
 	return func(securityScheme *SecurityScheme) {
@@ -136,36 +183,42 @@ type Endpoints struct {
 	Connect *Endpoint `json:"connect,omitempty"`
 }
 
-// ServeHTTP allows endpoints to serve itself using the builtin http mux
-func (e *Endpoints) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	var endpoint *Endpoint
-
-	switch req.Method {
+// Method returns the endpoint registered for the specified http method or nil if none exists
+func (e *Endpoints) Method(method string) *Endpoint {
+	switch strings.ToUpper(method) {
 	case "DELETE":
-		endpoint = e.Delete
+		return e.Delete
 	case "HEAD":
-		endpoint = e.Head
+		return e.Head
 	case "GET":
-		endpoint = e.Get
+		return e.Get
 	case "OPTIONS":
-		endpoint = e.Options
+		return e.Options
 	case "POST":
-		endpoint = e.Post
+		return e.Post
 	case "PUT":
-		endpoint = e.Put
+		return e.Put
 	case "PATCH":
-		endpoint = e.Patch
+		return e.Patch
 	case "TRACE":
-		endpoint = e.Trace
+		return e.Trace
 	case "CONNECT":
-		endpoint = e.Connect
+		return e.Connect
 	}
+	return nil
+}
+
+// ServeHTTP allows endpoints to serve itself using the builtin http mux
+func (e *Endpoints) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	endpoint := e.Method(req.Method)
 
 	if endpoint == nil || endpoint.Handler == nil {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
+	SetDeprecationHeaders(w.Header(), endpoint)
+
 	switch v := endpoint.Handler.(type) {
 	case func(w http.ResponseWriter, req *http.Request):
 		v(w, req)
@@ -179,6 +232,29 @@ func (e *Endpoints) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// mapEndpoints returns a new Endpoints containing the result of fn for each method; methods for which fn returns nil
+// are omitted
+func (e *Endpoints) mapEndpoints(fn func(endpoint *Endpoint) *Endpoint) *Endpoints {
+	apply := func(endpoint *Endpoint) *Endpoint {
+		if endpoint == nil {
+			return nil
+		}
+		return fn(endpoint)
+	}
+
+	return &Endpoints{
+		Delete:  apply(e.Delete),
+		Head:    apply(e.Head),
+		Get:     apply(e.Get),
+		Options: apply(e.Options),
+		Post:    apply(e.Post),
+		Put:     apply(e.Put),
+		Patch:   apply(e.Patch),
+		Trace:   apply(e.Trace),
+		Connect: apply(e.Connect),
+	}
+}
+
 // Walk calls the specified function for each method defined within the Endpoints
 func (e *Endpoints) Walk(fn func(endpoint *Endpoint)) {
 	if e.Delete != nil {
@@ -210,7 +286,9 @@ func (e *Endpoints) Walk(fn func(endpoint *Endpoint)) {
 	}
 }
 
-// API provides the top level encapsulation for the swagger definition
+// API provides the top level encapsulation for the swagger definition.  AddEndpoint and RemoveEndpoint may be called
+// while the api is being served; they replace, rather than modify, Paths and Definitions so a Snapshot is never
+// affected by later changes.  Other fields should not be modified once the api is in use
 type API struct {
 	Swagger             string                    `json:"swagger,omitempty"`
 	Info                Info                      `json:"info"`
@@ -223,6 +301,53 @@ type API struct {
 	SecurityDefinitions map[string]SecurityScheme `json:"securityDefinitions,omitempty"`
 	Security            *SecurityRequirement      `json:"security,omitempty"`
 	DocPath             string                    `json:"-"`
+
+	// DefaultResponse, when set, is declared as the "default" response of every endpoint that doesn't define one
+	DefaultResponse *Response `json:"-"`
+
+	// Envelope, when set, wraps the body of every successful response; see NewEnvelope
+	Envelope *Envelope `json:"-"`
+
+	// Catalog holds translations of the api's descriptions; see Localize
+	Catalog Catalog `json:"-"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
+
+	// state is allocated on first use and held by pointer, rather than embedding a mutex, so the api may be passed by
+	// value e.g. to json.Marshal
+	state unsafe.Pointer
+}
+
+// apiState guards the changes made by AddEndpoint and RemoveEndpoint; the generation counts them
+type apiState struct {
+	mu         sync.RWMutex
+	generation uint64
+}
+
+func (a *API) shared() *apiState {
+	if p := atomic.LoadPointer(&a.state); p != nil {
+		return (*apiState)(p)
+	}
+	atomic.CompareAndSwapPointer(&a.state, nil, unsafe.Pointer(&apiState{}))
+	return (*apiState)(atomic.LoadPointer(&a.state))
+}
+
+// Snapshot returns a shallow copy of the api that is safe to read while endpoints are added or removed concurrently;
+// its maps are shared and must not be modified
+func (a *API) Snapshot() *API {
+	v, _ := a.snapshot()
+	return v
+}
+
+// snapshot returns a Snapshot along with the generation it was taken at; the generation changes whenever an endpoint
+// is added or removed
+func (a *API) snapshot() (*API, uint64) {
+	state := a.shared()
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+
+	return a.clone(), state.generation
 }
 
 func (a *API) clone() *API {
@@ -237,132 +362,208 @@ func (a *API) clone() *API {
 		Host:                a.Host,
 		SecurityDefinitions: a.SecurityDefinitions,
 		Security:            a.Security,
+		DocPath:             a.DocPath,
+		DefaultResponse:     a.DefaultResponse,
+		Envelope:            a.Envelope,
+		Catalog:             a.Catalog,
+		Extensions:          a.Extensions,
 	}
 }
 
-func (a *API) addPath(e *Endpoint) {
-	if a.Paths == nil {
-		a.Paths = map[string]*Endpoints{}
-	}
-
-	v, ok := a.Paths[e.Path]
-	if !ok {
-		v = &Endpoints{}
-		a.Paths[e.Path] = v
-	}
-
-	switch strings.ToUpper(e.Method) {
+// setMethod sets the endpoint for the specified http method; returns false if the method is invalid
+func (e *Endpoints) setMethod(method string, endpoint *Endpoint) bool {
+	switch strings.ToUpper(method) {
 	case "DELETE":
-		v.Delete = e
+		e.Delete = endpoint
 	case "GET":
-		v.Get = e
+		e.Get = endpoint
 	case "HEAD":
-		v.Head = e
+		e.Head = endpoint
 	case "OPTIONS":
-		v.Options = e
+		e.Options = endpoint
 	case "POST":
-		v.Post = e
+		e.Post = endpoint
 	case "PUT":
-		v.Put = e
+		e.Put = endpoint
 	case "PATCH":
-		v.Patch = e
+		e.Patch = endpoint
 	case "TRACE":
-		v.Trace = e
+		e.Trace = endpoint
 	case "CONNECT":
-		v.Connect = e
+		e.Connect = endpoint
 	default:
+		return false
+	}
+	return true
+}
+
+// copyPaths returns a copy of the paths in which the Endpoints of path may be modified
+func copyPaths(paths map[string]*Endpoints, path string) (map[string]*Endpoints, *Endpoints) {
+	v := make(map[string]*Endpoints, len(paths)+1)
+	for k, endpoints := range paths {
+		v[k] = endpoints
+	}
+
+	endpoints := &Endpoints{}
+	if existing, ok := paths[path]; ok {
+		*endpoints = *existing
+	}
+	v[path] = endpoints
+
+	return v, endpoints
+}
+
+// copyEndpoint returns a copy of the endpoint whose responses and media types may be modified without affecting e,
+// which may be shared with earlier snapshots
+func copyEndpoint(e *Endpoint) *Endpoint {
+	c := *e
+	c.Produces = append([]string(nil), e.Produces...)
+	if e.Responses != nil {
+		c.Responses = make(map[string]Response, len(e.Responses))
+		for k, v := range e.Responses {
+			c.Responses[k] = v
+		}
+	}
+	return &c
+}
+
+func (a *API) addPath(e *Endpoint) {
+	paths, v := copyPaths(a.Paths, e.Path)
+	if !v.setMethod(e.Method, e) {
 		panic(fmt.Errorf("invalid method, %v", e.Method))
 	}
+	a.Paths = paths
 }
 
+// addDefinition adds the definitions of the endpoint's parameters and responses that aren't already defined; the
+// definitions are copied, at most once, only if there are new ones as earlier snapshots share the map
 func (a *API) addDefinition(e *Endpoint) {
-	if a.Definitions == nil {
-		a.Definitions = map[string]Object{}
-	}
-
-	if e.Parameters != nil {
-		for _, p := range e.Parameters {
-			if p.Schema != nil {
-				def := define(p.Schema.TypeAlias, p.Schema.Prototype)
-				for k, v := range def {
-					if _, ok := a.Definitions[k]; !ok {
-						a.Definitions[k] = v
-					}
-				}
+	var added map[string]Object
+	add := func(def map[string]Object) {
+		for k, v := range def {
+			if _, ok := a.Definitions[k]; ok {
+				continue
+			}
+			if added == nil {
+				added = map[string]Object{}
+			}
+			if _, ok := added[k]; !ok {
+				added[k] = v
 			}
 		}
 	}
 
-	if e.Responses != nil {
-		for _, response := range e.Responses {
-			if response.Schema != nil {
-				def := define(response.Schema.TypeAlias, response.Schema.Prototype)
-				for k, v := range def {
-					if _, ok := a.Definitions[k]; !ok {
-						a.Definitions[k] = v
-					}
-				}
+	for _, p := range e.Parameters {
+		if p.Schema != nil && p.Schema.Prototype != nil {
+			add(define(p.Schema.TypeAlias, p.Schema.Prototype))
+		}
+	}
+
+	for _, response := range e.Responses {
+		if response.Schema != nil && (response.Schema.Prototype != nil || response.Schema.Payload != nil) {
+			if response.Schema.Payload != nil {
+				add(a.Envelope.define(response.Schema))
+			} else {
+				add(define(response.Schema.TypeAlias, response.Schema.Prototype))
 			}
 		}
 	}
-}
 
-// AddEndpoint adds the specified endpoint to the API definition; to generate an endpoint use ```endpoint.New```
-func (a *API) AddEndpoint(e *Endpoint) {
-	a.addPath(e)
-	a.addDefinition(e)
+	if len(added) == 0 {
+		return
+	}
+
+	definitions := make(map[string]Object, len(a.Definitions)+len(added))
+	for k, v := range a.Definitions {
+		definitions[k] = v
+	}
+	for k, v := range added {
+		definitions[k] = v
+	}
+	a.Definitions = definitions
 }
 
-// Handler is a factory method that generates an http.HandlerFunc; if enableCors is true, then the handler will generate
-// cors headers
-func (a *API) Handler(enableCors bool) http.HandlerFunc {
-	mux := &sync.Mutex{}
-	byHostAndScheme := map[string]*API{}
+func (a *API) addDefaultResponse(e *Endpoint) {
+	if a.DefaultResponse == nil {
+		return
+	}
 
-	return func(w http.ResponseWriter, req *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+	if e.Responses == nil {
+		e.Responses = map[string]Response{}
+	}
 
-		if enableCors {
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, api_key, Authorization")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, PUT")
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-		}
+	if _, ok := e.Responses["default"]; !ok {
+		e.Responses["default"] = *a.DefaultResponse
+	}
+}
 
-		w.WriteHeader(http.StatusOK)
+// addMediaTypes merges the media types of the endpoint's responses into its produces, in order of status code
+func (a *API) addMediaTypes(e *Endpoint) {
+	keys := make([]string, 0, len(e.Responses))
+	for key := range e.Responses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		// customize the swagger header based on host
-		//
-		scheme := ""
-		if req.TLS != nil {
-			scheme = "https"
-		}
-		if v := req.Header.Get("X-Forwarded-Proto"); v != "" {
-			scheme = v
-		}
-		if scheme == "" {
-			scheme = req.URL.Scheme
-		}
-		if scheme == "" {
-			scheme = "http"
+	for _, key := range keys {
+		for _, mediaType := range e.Responses[key].MediaTypes {
+			if !containsString(e.Produces, mediaType) {
+				e.Produces = append(e.Produces, mediaType)
+			}
 		}
+	}
+}
 
-		hostAndScheme := req.Host + ":" + scheme
-		mux.Lock()
-		v, ok := byHostAndScheme[hostAndScheme]
-		if !ok {
-			v = a.clone()
-			v.Host = req.Host
-			v.Schemes = []string{scheme}
-			byHostAndScheme[hostAndScheme] = v
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
 		}
-		mux.Unlock()
+	}
+	return false
+}
+
+// AddEndpoint adds a copy of the specified endpoint to the API definition, replacing any endpoint with the same method
+// and path; to generate an endpoint use ```endpoint.New```
+func (a *API) AddEndpoint(e *Endpoint) {
+	state := a.shared()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	e = copyEndpoint(e)
+	a.addDefaultResponse(e)
+	a.addEnvelope(e)
+	a.addMediaTypes(e)
+	a.addPath(e)
+	a.addDefinition(e)
+	state.generation++
+}
+
+// RemoveEndpoint removes the endpoint with the specified method and path, relative to the basePath e.g. /pets/{id};
+// returns false if there is no such endpoint.  Definitions are retained as they may be shared with other endpoints
+func (a *API) RemoveEndpoint(method, path string) bool {
+	state := a.shared()
+	state.mu.Lock()
+	defer state.mu.Unlock()
 
-		json.NewEncoder(w).Encode(v)
+	if existing, ok := a.Paths[path]; !ok || existing.Method(method) == nil {
+		return false
 	}
+
+	paths, v := copyPaths(a.Paths, path)
+	v.setMethod(method, nil)
+	if *v == (Endpoints{}) {
+		delete(paths, path)
+	}
+	a.Paths = paths
+	state.generation++
+
+	return true
 }
 
 // Walk invoke the callback for each endpoints defined in the swagger doc
 func (a *API) Walk(callback func(path string, endpoints *Endpoint)) {
+	a = a.Snapshot()
 	for rawPath, endpoints := range a.Paths {
 		u := path.Join(a.BasePath, rawPath)
 		endpoints.Walk(func(endpoint *Endpoint) {
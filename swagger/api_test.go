@@ -1,12 +1,15 @@
 package swagger
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -121,3 +124,103 @@ func TestOAuth2Scope(t *testing.T) {
 	assert.Equal(t, "read data", scheme.Scopes["read"])
 	assert.Equal(t, "write data", scheme.Scopes["write"])
 }
+
+func TestRemoveEndpoint(t *testing.T) {
+	api := &API{}
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets"})
+	api.AddEndpoint(&Endpoint{Method: "POST", Path: "/pets"})
+
+	snapshot := api.Snapshot()
+
+	assert.True(t, api.RemoveEndpoint("GET", "/pets"))
+	assert.False(t, api.RemoveEndpoint("GET", "/pets"))
+	assert.False(t, api.RemoveEndpoint("GET", "/unknown"))
+	assert.Nil(t, api.Paths["/pets"].Get)
+	assert.NotNil(t, api.Paths["/pets"].Post)
+
+	assert.True(t, api.RemoveEndpoint("post", "/pets"))
+	assert.NotContains(t, api.Paths, "/pets")
+
+	// the snapshot is unaffected
+	assert.NotNil(t, snapshot.Paths["/pets"].Get)
+	assert.NotNil(t, snapshot.Paths["/pets"].Post)
+}
+
+func TestAddDefinition(t *testing.T) {
+	type Pet struct {
+		Name string `json:"name"`
+	}
+	pets := func(method string) *Endpoint {
+		return &Endpoint{Method: method, Path: "/pets", Responses: map[string]Response{"200": {Schema: MakeSchema("", Pet{})}}}
+	}
+
+	api := &API{}
+	api.AddEndpoint(pets("GET"))
+	snapshot := api.Snapshot()
+	assert.Contains(t, snapshot.Definitions, "swaggerPet")
+
+	// the definitions are only copied when the endpoint adds new ones
+	api.AddEndpoint(pets("POST"))
+	assert.Equal(t, fmt.Sprintf("%p", snapshot.Definitions), fmt.Sprintf("%p", api.Definitions))
+
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/problem", Responses: map[string]Response{"default": ProblemResponse("error")}})
+	assert.Contains(t, api.Definitions, "swaggerProblemDetails")
+	assert.NotContains(t, snapshot.Definitions, "swaggerProblemDetails")
+}
+
+func TestHandlerReflectsChanges(t *testing.T) {
+	api := &API{}
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets"})
+	h := api.Handler(false)
+
+	paths := func() map[string]interface{} {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "http://example.com/docs", nil))
+
+		v := struct {
+			Paths map[string]interface{} `json:"paths"`
+		}{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &v))
+		return v.Paths
+	}
+
+	assert.Contains(t, paths(), "/pets")
+
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/owners"})
+	assert.Contains(t, paths(), "/owners")
+
+	api.RemoveEndpoint("GET", "/pets")
+	assert.NotContains(t, paths(), "/pets")
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	type Pet struct {
+		Name string
+	}
+
+	api := &API{}
+	h := api.Handler(false)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			p := fmt.Sprintf("/pets%v", i)
+			api.AddEndpoint(&Endpoint{
+				Method:    "GET",
+				Path:      p,
+				Responses: map[string]Response{"200": {Schema: &Schema{Prototype: Pet{}}}},
+			})
+			api.Lookup("GET", p)
+			api.RemoveEndpoint("GET", p)
+		}(i)
+		go func() {
+			defer wg.Done()
+			h(httptest.NewRecorder(), httptest.NewRequest("GET", "/docs", nil))
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, api.Paths)
+}
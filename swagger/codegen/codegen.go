@@ -0,0 +1,195 @@
+// Package codegen generates client code from a swagger.API
+package codegen
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Option provides configuration options to the generators
+type Option func(c *config)
+
+type config struct {
+	pkg string
+}
+
+// Package sets the package name of the generated go client; defaults to client
+func Package(name string) Option {
+	return func(c *config) {
+		c.pkg = name
+	}
+}
+
+func newConfig(options []Option) *config {
+	c := &config{
+		pkg: "client",
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// operations returns the api's endpoints in the order of swagger.API.Operations with the basePath joined to their
+// paths
+func operations(api *swagger.API) []swagger.Operation {
+	ops := api.Operations()
+	for i := range ops {
+		ops[i].Path = path.Join(api.BasePath, ops[i].Path)
+	}
+	return ops
+}
+
+// operationName returns the exported identifier for the operation
+func operationName(op swagger.Operation) string {
+	if op.Endpoint.OperationID != "" {
+		return exported(op.Endpoint.OperationID)
+	}
+	return exported(strings.ToLower(op.Method) + " " + op.Path)
+}
+
+// exported converts v into an exported identifier e.g. page_size => PageSize
+func exported(v string) string {
+	words := strings.FieldsFunc(v, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	b := &strings.Builder{}
+	for _, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		b.WriteRune(unicode.ToUpper(r))
+		b.WriteString(word[size:])
+	}
+
+	// identifiers starting with a digit or a letter without case, e.g. 名前, can't be exported as is
+	name := b.String()
+	if r, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(r) {
+		name = "X" + name
+	}
+	return name
+}
+
+// unexported converts v into an unexported identifier e.g. page_size => pageSize
+func unexported(v string) string {
+	name := exported(v)
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// typeNames assigns every definition a distinct type name that doesn't collide with the reserved names declared by
+// the generated runtime; colliding names are suffixed e.g. Error => ErrorModel
+func typeNames(api *swagger.API, reserved []string) map[string]string {
+	taken := map[string]bool{}
+	for _, name := range reserved {
+		taken[name] = true
+	}
+	for _, op := range operations(api) {
+		taken[operationName(op)+"Params"] = true
+	}
+
+	names := map[string]string{}
+	for _, name := range definitionNames(api) {
+		typ := exported(name)
+		for i := 1; taken[typ]; i++ {
+			typ = exported(name) + "Model"
+			if i > 1 {
+				typ += strconv.Itoa(i)
+			}
+		}
+		taken[typ] = true
+		names[name] = typ
+	}
+	return names
+}
+
+func definitionName(ref string) string {
+	return strings.TrimPrefix(ref, "#/definitions/")
+}
+
+func definitionNames(api *swagger.API) []string {
+	names := make([]string, 0, len(api.Definitions))
+	for name := range api.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// polymorphic returns the implementations of the named definition when it carries a discriminator
+func polymorphic(api *swagger.API, name string) []string {
+	if api.Definitions[name].Discriminator == "" {
+		return nil
+	}
+	return swagger.Implementations(api.Definitions, name)
+}
+
+func propertyNames(obj swagger.Object) []string {
+	names := make([]string, 0, len(obj.Properties))
+	for name := range obj.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isRequired(obj swagger.Object, name string) bool {
+	for _, v := range obj.Required {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// successResponse returns the schema of the lowest declared 2xx response or nil when there is none
+func successResponse(e *swagger.Endpoint) *swagger.Schema {
+	best := 0
+	var schema *swagger.Schema
+	for key, response := range e.Responses {
+		code, err := strconv.Atoi(key)
+		if err != nil || code < 200 || code >= 300 {
+			continue
+		}
+		if best == 0 || code < best {
+			best, schema = code, response.Schema
+		}
+	}
+	return schema
+}
+
+// securityRequirements returns the effective security requirement of the endpoint as a list of alternatives where
+// each alternative lists the scheme names that must all be satisfied
+func securityRequirements(api *swagger.API, e *swagger.Endpoint) [][]string {
+	requirement := api.EffectiveSecurity(e)
+	if requirement == nil {
+		return nil
+	}
+
+	var alternatives [][]string
+	for _, r := range requirement.Requirements {
+		names := make([]string, 0, len(r))
+		for name := range r {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		alternatives = append(alternatives, names)
+	}
+	return alternatives
+}
+
+func schemeNames(api *swagger.API) []string {
+	names := make([]string, 0, len(api.SecurityDefinitions))
+	for name := range api.SecurityDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
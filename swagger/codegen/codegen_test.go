@@ -0,0 +1,206 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type Category struct {
+	Name string `json:"name" desc:"category name"`
+}
+
+type Pet struct {
+	ID       int64             `json:"id" required:"true" x-go-name:"ID"`
+	Name     string            `json:"name" required:"true" desc:"the pet's name"`
+	Weight   float32           `json:"weight"`
+	Tags     []string          `json:"tags"`
+	Category *Category         `json:"category"`
+	Siblings []Pet             `json:"siblings"`
+	Status   string            `json:"status" enum:"available,pending,sold"`
+	Labels   map[string]string `json:"labels"`
+	Nickname string            `json:"nickname" deprecated:"true"`
+	Owner    Owner             `json:"owner"`
+}
+
+type Owner interface {
+	owner()
+}
+
+type Person struct {
+	Name string `json:"name" required:"true"`
+}
+
+func (Person) owner() {}
+
+type Shelter struct {
+	City string `json:"city"`
+}
+
+func (Shelter) owner() {}
+
+func init() {
+	swagger.RegisterPolymorphic((*Owner)(nil), "kind",
+		swagger.Implements("person", Person{}),
+		swagger.Implements("shelter", Shelter{}),
+	)
+}
+
+func petstore() *swagger.API {
+	api := &swagger.API{
+		Swagger:  "2.0",
+		BasePath: "/api",
+		Info:     swagger.Info{Title: "Petstore"},
+		SecurityDefinitions: map[string]swagger.SecurityScheme{
+			"api_key": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			"basic":   {Type: "basic"},
+			"oauth":   {Type: "oauth2", Flow: "accessCode"},
+			"jwt":     {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			"session": {Type: "apiKey", Name: "session", In: "cookie"},
+		},
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{{"api_key": {}}, {"oauth": {"read"}}}},
+	}
+
+	api.AddEndpoint(endpoint.Get("/pets", "list pets",
+		endpoint.OperationID("listPets"),
+		endpoint.Query("limit", "integer", "maximum number of pets", false),
+		endpoint.Query("status", "string", "status filter", true),
+		endpoint.Response(http.StatusOK, []Pet{}, "", "the pets"),
+	))
+	api.AddEndpoint(endpoint.Post("/pets", "create a pet",
+		endpoint.OperationID("createPet"),
+		endpoint.Body(Pet{}, "the pet", true),
+		endpoint.Response(http.StatusCreated, Pet{}, "", "created"),
+		endpoint.Security("basic"),
+	))
+	api.AddEndpoint(endpoint.Get("/pets/{id}", "find a pet by id",
+		endpoint.OperationID("getPet"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusOK, Pet{}, "", "the pet"),
+		endpoint.StandardErrors(),
+	))
+	api.AddEndpoint(endpoint.Delete("/pets/{id}", "",
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusNoContent, nil, "", "deleted"),
+		endpoint.NoSecurity(),
+	))
+	api.AddEndpoint(endpoint.Get("/pets/{id}/photo", "download the pet's photo",
+		endpoint.OperationID("getPetPhoto"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.File(http.StatusOK, "the photo", "image/png"),
+	))
+	api.AddEndpoint(endpoint.Get("/pets/{id}/name", "the pet's name",
+		endpoint.OperationID("getPetName"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Deprecated(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC), "/api/pets/{id}"),
+		endpoint.Response(http.StatusOK, "", "", "the name"),
+	))
+
+	upload := endpoint.Post("/pets/{id}/photo", "upload the pet's photo",
+		endpoint.OperationID("uploadPetPhoto"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusNoContent, nil, "", "uploaded"),
+	)
+	upload.Parameters = append(upload.Parameters,
+		swagger.Parameter{In: "formData", Name: "caption", Type: "string", Description: "a caption for the photo"},
+		swagger.Parameter{In: "formData", Name: "photo", Type: "file", Required: true},
+	)
+	api.AddEndpoint(upload)
+
+	// collides with the Error type declared, or used, by the runtimes
+	api.Definitions["Error"] = swagger.Object{
+		Type:       "object",
+		Properties: map[string]swagger.Property{"code": {Type: "string"}},
+	}
+
+	return api
+}
+
+func golden(t *testing.T, name string, actual []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.Nil(t, ioutil.WriteFile(path, actual, 0644))
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestGo(t *testing.T) {
+	src, err := Go(petstore(), Package("petstore"))
+	assert.Nil(t, err)
+	golden(t, "petstore.go.golden", src)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "petstore.go", src, 0)
+	assert.Nil(t, err)
+
+	conf := types.Config{Importer: importer.Default()}
+	_, err = conf.Check("petstore", fset, []*ast.File{file}, nil)
+	assert.Nil(t, err)
+}
+
+func TestTypeScript(t *testing.T) {
+	src, err := TypeScript(petstore())
+	assert.Nil(t, err)
+	golden(t, "petstore.ts.golden", src)
+}
+
+func TestExported(t *testing.T) {
+	assert.Equal(t, "PageSize", exported("page_size"))
+	assert.Equal(t, "GetPetsId", exported("getPetsId"))
+	assert.Equal(t, "X1st", exported("1st"))
+	assert.Equal(t, "Émile", exported("émile"))
+	assert.Equal(t, "X名前", exported("名前"))
+	assert.Equal(t, "X", exported("-"))
+	assert.Equal(t, "pageSize", unexported("page_size"))
+	assert.Equal(t, "émile", unexported("Émile"))
+}
+
+func TestTypeNames(t *testing.T) {
+	api := &swagger.API{Definitions: map[string]swagger.Object{
+		"Client":   {Type: "object"},
+		"PetItem":  {Type: "object"},
+		"pet_item": {Type: "object"},
+		"pet-item": {Type: "object"},
+	}}
+	api.AddEndpoint(endpoint.Get("/pets", "", endpoint.OperationID("listPets")))
+	api.Definitions["ListPetsParams"] = swagger.Object{Type: "object"}
+
+	assert.Equal(t, map[string]string{
+		"Client":         "ClientModel",
+		"ListPetsParams": "ListPetsParamsModel",
+		"PetItem":        "PetItem",
+		"pet-item":       "PetItemModel",
+		"pet_item":       "PetItemModel2",
+	}, typeNames(api, []string{"Client"}))
+}
+
+func TestGoFromJSON(t *testing.T) {
+	data, err := json.Marshal(petstore())
+	assert.Nil(t, err)
+
+	api, err := swagger.Load(bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	src, err := Go(api, Package("petstore"))
+	assert.Nil(t, err)
+	golden(t, "petstore.go.golden", src)
+}
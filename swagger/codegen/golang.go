@@ -0,0 +1,630 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Go generates the source of a typed go client package for the api; the generated package only depends on the
+// standard library
+func Go(api *swagger.API, options ...Option) ([]byte, error) {
+	reserved := []string{"Client", "ClientOption", "Error", "NewClient", "Problem", "WithHTTPClient"}
+	for _, name := range schemeNames(api) {
+		reserved = append(reserved, "With"+exported(name))
+	}
+
+	g := &goGenerator{
+		api:    api,
+		config: newConfig(options),
+		types:  typeNames(api, reserved),
+		buf:    &bytes.Buffer{},
+	}
+
+	g.printf(goHeader, g.config.pkg, api.Info.Title)
+	g.definitions()
+	g.security()
+	for _, op := range operations(api) {
+		g.operation(op)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated source: %v", err)
+	}
+	return src, nil
+}
+
+type goGenerator struct {
+	api    *swagger.API
+	config *config
+	types  map[string]string
+	buf    *bytes.Buffer
+}
+
+func (g *goGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+func (g *goGenerator) comment(indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		g.printf("%v// %v\n", indent, strings.TrimSpace(line))
+	}
+}
+
+func (g *goGenerator) definitions() {
+	for _, name := range definitionNames(g.api) {
+		obj := g.api.Definitions[name]
+		if implementations := polymorphic(g.api, name); len(implementations) > 0 {
+			g.printf("\n// %v is generated from the %v definition; decode it into one of the implementations selected by\n", g.types[name], name)
+			types := make([]string, 0, len(implementations))
+			for _, impl := range implementations {
+				types = append(types, g.types[impl])
+			}
+			g.printf("// its %v property: %v\n", obj.Discriminator, strings.Join(types, ", "))
+			g.printf("type %v = json.RawMessage\n", g.types[name])
+			continue
+		}
+
+		obj = swagger.Flatten(obj, g.api.Definitions)
+		if obj.Type != "object" {
+			// primitive definitions are referenced by their go type
+			continue
+		}
+
+		g.printf("\n// %v is generated from the %v definition\n", g.types[name], name)
+		if obj.Description != "" {
+			g.printf("//\n")
+			g.comment("", obj.Description)
+		}
+		g.printf("type %v struct {\n", g.types[name])
+		for _, prop := range propertyNames(obj) {
+			p := obj.Properties[prop]
+			if p.Description != "" {
+				g.comment("\t", p.Description)
+			}
+			if p.Deprecated {
+				g.comment("\t", "Deprecated: this field is deprecated")
+			}
+
+			tag := prop
+			if !isRequired(obj, prop) {
+				tag += ",omitempty"
+			}
+			field := exported(prop)
+			if name, ok := p.Extensions["x-go-name"].(string); ok && name != "" {
+				field = name
+			}
+			g.printf("\t%v %v `json:%q`\n", field, g.propertyType(p), tag)
+		}
+		g.printf("}\n")
+	}
+}
+
+func (g *goGenerator) security() {
+	for _, name := range schemeNames(g.api) {
+		scheme := g.api.SecurityDefinitions[name]
+		option := "With" + exported(name)
+
+		switch scheme.Kind() {
+		case "basic":
+			g.printf("\n// %v authenticates requests with the %v basic security scheme\n", option, name)
+			g.printf("func %v(username, password string) ClientOption {\n", option)
+			g.printf("\treturn credential(%q, func(req *http.Request) {\n\t\treq.SetBasicAuth(username, password)\n\t})\n}\n", name)
+
+		case "apiKey":
+			g.printf("\n// %v authenticates requests with the %v api key security scheme\n", option, name)
+			g.printf("func %v(key string) ClientOption {\n", option)
+			g.printf("\treturn credential(%q, func(req *http.Request) {\n", name)
+			switch scheme.In {
+			case "query":
+				g.printf("\t\tquery := req.URL.Query()\n\t\tquery.Set(%q, key)\n\t\treq.URL.RawQuery = query.Encode()\n", scheme.Name)
+			case "cookie":
+				g.printf("\t\treq.AddCookie(&http.Cookie{Name: %q, Value: key})\n", scheme.Name)
+			default:
+				g.printf("\t\treq.Header.Set(%q, key)\n", scheme.Name)
+			}
+			g.printf("\t})\n}\n")
+
+		case "bearer", "oauth2":
+			g.printf("\n// %v authenticates requests with a bearer token issued by the %v %v security scheme\n", option, name, scheme.Type)
+			g.printf("func %v(token string) ClientOption {\n", option)
+			g.printf("\treturn credential(%q, func(req *http.Request) {\n\t\treq.Header.Set(\"Authorization\", \"Bearer \"+token)\n\t})\n}\n", name)
+		}
+	}
+}
+
+func (g *goGenerator) operation(op swagger.Operation) {
+	e := op.Endpoint
+	name := operationName(op)
+
+	var (
+		params []swagger.Parameter
+		form   bool
+	)
+	for _, p := range e.Parameters {
+		switch p.In {
+		case "path", "query", "header", "body":
+			params = append(params, p)
+		case "formData":
+			params = append(params, p)
+			form = true
+		}
+	}
+
+	signature := "ctx context.Context"
+	if len(params) > 0 {
+		g.printf("\n// %vParams are the parameters of %v\n", name, name)
+		g.printf("type %vParams struct {\n", name)
+		for _, p := range params {
+			if p.Description != "" {
+				g.comment("\t", p.Description)
+			}
+			g.printf("\t%v %v\n", paramField(p), g.paramType(p))
+		}
+		g.printf("}\n")
+		signature += ", params " + name + "Params"
+	}
+
+	var (
+		schema  = successResponse(e)
+		typ     string
+		pointer bool
+		returns = "error"
+	)
+	if schema != nil {
+		typ, pointer = g.schemaType(schema)
+		returns = "(" + typ + ", error)"
+		if pointer {
+			returns = "(*" + typ + ", error)"
+		}
+	}
+
+	summary := e.Summary
+	if summary == "" {
+		summary = "calls " + op.Method + " " + op.Path
+	}
+	g.printf("\n")
+	g.comment("", name+" "+summary)
+	if e.Deprecated {
+		g.printf("//\n")
+		g.comment("", deprecation(e))
+	}
+	g.printf("func (c *Client) %v(%v) %v {\n", name, signature, returns)
+	g.printf("\tquery := url.Values{}\n\theader := http.Header{}\n\tvar body interface{}\n")
+	if form {
+		g.printf("\tfields := newForm()\n")
+	}
+
+	for _, p := range params {
+		field := "params." + paramField(p)
+		switch {
+		case p.In == "formData" && p.Type == "file":
+			g.printf("\tif %v != nil {\n\t\tfields.files[%q] = %v\n\t}\n", field, p.Name, field)
+		case p.In == "body":
+			if pt := g.paramType(p); strings.HasPrefix(pt, "*") || strings.HasPrefix(pt, "[]") || pt == "interface{}" {
+				g.printf("\tif %v != nil {\n\t\tbody = %v\n\t}\n", field, field)
+			} else {
+				g.printf("\tbody = %v\n", field)
+			}
+		case p.In == "path":
+			// interpolated by pathExpr
+		case p.Type == "array":
+			g.printf("\tfor _, v := range %v {\n\t\t%v.Add(%q, fmt.Sprint(v))\n\t}\n", field, collection(p), p.Name)
+		case p.Required:
+			g.printf("\t%v.Set(%q, fmt.Sprint(%v))\n", collection(p), p.Name, field)
+		default:
+			g.printf("\tif %v != nil {\n\t\t%v.Set(%q, fmt.Sprint(*%v))\n\t}\n", field, collection(p), p.Name, field)
+		}
+	}
+
+	if form {
+		g.printf("\tbody = fields\n")
+	}
+
+	call := fmt.Sprintf("c.do(ctx, %q, %v, query, header, body, %v, ", op.Method, g.pathExpr(op.Path), g.securityExpr(e))
+	switch {
+	case schema == nil:
+		g.printf("\treturn %vnil)\n}\n", call)
+	case pointer:
+		g.printf("\tvar out %v\n\tif err := %v&out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n", typ, call)
+	default:
+		g.printf("\tvar out %v\n\terr := %v&out)\n\treturn out, err\n}\n", typ, call)
+	}
+}
+
+func deprecation(e *swagger.Endpoint) string {
+	text := "Deprecated: this operation is deprecated"
+	if !e.Sunset.IsZero() {
+		text += " and will be removed after " + e.Sunset.UTC().Format("2006-01-02")
+	}
+	if e.Successor != "" {
+		text += "; use " + e.Successor + " instead"
+	}
+	return text
+}
+
+func collection(p swagger.Parameter) string {
+	switch p.In {
+	case "header":
+		return "header"
+	case "formData":
+		return "fields.values"
+	}
+	return "query"
+}
+
+func paramField(p swagger.Parameter) string {
+	if p.In == "body" {
+		return "Body"
+	}
+	return exported(p.Name)
+}
+
+// pathExpr converts a path template into a go expression that interpolates the path parameters
+func (g *goGenerator) pathExpr(template string) string {
+	var parts []string
+	literal := ""
+	for _, segment := range strings.Split(template, "/") {
+		if segment == "" {
+			continue
+		}
+
+		literal += "/"
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			parts = append(parts, strconv.Quote(literal))
+			parts = append(parts, "url.PathEscape(fmt.Sprint(params."+exported(segment[1:len(segment)-1])+"))")
+			literal = ""
+			continue
+		}
+		literal += segment
+	}
+
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, "+")
+}
+
+func (g *goGenerator) securityExpr(e *swagger.Endpoint) string {
+	alternatives := securityRequirements(g.api, e)
+	if len(alternatives) == 0 {
+		return "nil"
+	}
+
+	values := make([]string, 0, len(alternatives))
+	for _, names := range alternatives {
+		quoted := make([]string, 0, len(names))
+		for _, name := range names {
+			quoted = append(quoted, strconv.Quote(name))
+		}
+		values = append(values, "{"+strings.Join(quoted, ", ")+"}")
+	}
+	return "[][]string{" + strings.Join(values, ", ") + "}"
+}
+
+func (g *goGenerator) paramType(p swagger.Parameter) string {
+	if p.In == "body" {
+		if p.Schema == nil {
+			return "interface{}"
+		}
+		typ, pointer := g.schemaType(p.Schema)
+		if pointer {
+			return "*" + typ
+		}
+		return typ
+	}
+
+	typ := primitiveGoType(p.Type, p.Format)
+	if p.Type == "array" {
+		return "[]string"
+	}
+	if p.Type == "file" {
+		return typ
+	}
+	if p.In != "path" && !p.Required {
+		return "*" + typ
+	}
+	return typ
+}
+
+// schemaType returns the go type of the schema and whether it should be handled by pointer
+func (g *goGenerator) schemaType(schema *swagger.Schema) (string, bool) {
+	switch {
+	case schema.Ref != "":
+		typ := g.refType(schema.Ref, false)
+		_, isObject := g.object(schema.Ref)
+		return typ, isObject
+	case schema.Type == "array" && schema.Items != nil:
+		return "[]" + g.itemsType(schema.Items), false
+	default:
+		return primitiveGoType(schema.Type, ""), false
+	}
+}
+
+func (g *goGenerator) object(ref string) (swagger.Object, bool) {
+	obj, ok := g.api.Definitions[definitionName(ref)]
+	if len(polymorphic(g.api, definitionName(ref))) > 0 {
+		return obj, false
+	}
+	return obj, ok && (obj.Type == "object" || len(obj.AllOf) > 0)
+}
+
+func (g *goGenerator) refType(ref string, pointer bool) string {
+	obj, ok := g.api.Definitions[definitionName(ref)]
+	switch {
+	case !ok:
+		return "json.RawMessage"
+	case len(polymorphic(g.api, definitionName(ref))) > 0:
+		return g.types[definitionName(ref)]
+	case obj.Type != "object" && len(obj.AllOf) == 0:
+		return primitiveGoType(obj.Type, obj.Format)
+	case pointer:
+		return "*" + g.types[definitionName(ref)]
+	default:
+		return g.types[definitionName(ref)]
+	}
+}
+
+func (g *goGenerator) itemsType(items *swagger.Items) string {
+	if items.Ref != "" {
+		return g.refType(items.Ref, false)
+	}
+	return primitiveGoType(items.Type, items.Format)
+}
+
+func (g *goGenerator) propertyType(p swagger.Property) string {
+	switch {
+	case p.Ref != "":
+		return g.refType(p.Ref, true)
+	case p.Type == "array" && p.Items != nil:
+		return "[]" + g.itemsType(p.Items)
+	case p.Type == "array":
+		return "[]interface{}"
+	case p.AdditionalProperties != nil:
+		return "map[string]" + g.itemsType(p.AdditionalProperties)
+	default:
+		return primitiveGoType(p.Type, p.Format)
+	}
+}
+
+func primitiveGoType(typ, format string) string {
+	switch typ {
+	case "integer":
+		if format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "string":
+		return "string"
+	case "object":
+		return "map[string]interface{}"
+	case "file":
+		return "[]byte"
+	}
+	return "interface{}"
+}
+
+const goHeader = `// Code generated by docs-gen. DO NOT EDIT.
+
+// Package %[1]v is a client for the %[2]v api
+package %[1]v
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the api
+type Client struct {
+	// BaseURL is the scheme and host the api is served from e.g. https://api.example.com
+	BaseURL string
+
+	// HTTPClient sends the requests; defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	credentials map[string]func(req *http.Request)
+}
+
+// ClientOption customizes the Client
+type ClientOption func(c *Client)
+
+// WithHTTPClient sets the http.Client used to send requests
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient = hc
+	}
+}
+
+func credential(scheme string, fn func(req *http.Request)) ClientOption {
+	return func(c *Client) {
+		c.credentials[scheme] = fn
+	}
+}
+
+// NewClient constructs a Client for the api served from baseURL
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	c := &Client{
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:  http.DefaultClient,
+		credentials: map[string]func(req *http.Request){},
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+}
+
+// Error is returned when the api responds with a status other than 2xx
+type Error struct {
+	StatusCode int
+	Body       []byte
+
+	// Problem is set when the response is application/problem+json
+	Problem *Problem
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	if e.Problem != nil && e.Problem.Detail != "" {
+		return fmt.Sprintf("%%d %%v: %%v", e.StatusCode, e.Problem.Title, e.Problem.Detail)
+	}
+	return fmt.Sprintf("%%d %%v", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body interface{}, security [][]string, out interface{}) error {
+	var (
+		r           io.Reader
+		contentType string
+	)
+	switch b := body.(type) {
+	case nil:
+	case *form:
+		var err error
+		if r, contentType, err = b.encode(); err != nil {
+			return err
+		}
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.URL.RawQuery = query.Encode()
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json, */*;q=0.8")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	for _, schemes := range security {
+		if c.authorize(req, schemes) {
+			break
+		}
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		e := &Error{StatusCode: resp.StatusCode, Body: data}
+		if strings.Contains(resp.Header.Get("Content-Type"), "problem+json") {
+			p := &Problem{}
+			if json.Unmarshal(data, p) == nil {
+				e.Problem = p
+			}
+		}
+		return e
+	}
+
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// form is the body of an operation with formData parameters; it's sent as multipart/form-data when it carries files
+// and as application/x-www-form-urlencoded otherwise
+type form struct {
+	values url.Values
+	files  map[string][]byte
+}
+
+func newForm() *form {
+	return &form{values: url.Values{}, files: map[string][]byte{}}
+}
+
+func (f *form) encode() (io.Reader, string, error) {
+	if len(f.files) == 0 {
+		return strings.NewReader(f.values.Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for name, values := range f.values {
+		for _, v := range values {
+			if err := w.WriteField(name, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for name, data := range f.files {
+		part, err := w.CreateFormFile(name, name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf, w.FormDataContentType(), nil
+}
+
+// authorize applies the credentials of every scheme when all of them have been configured
+func (c *Client) authorize(req *http.Request, schemes []string) bool {
+	for _, scheme := range schemes {
+		if _, ok := c.credentials[scheme]; !ok {
+			return false
+		}
+	}
+
+	for _, scheme := range schemes {
+		c.credentials[scheme](req)
+	}
+	return true
+}
+`
@@ -0,0 +1,499 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// TypeScript generates a typescript module for the api containing an interface for each definition and a fetch based
+// client with one function per operation
+func TypeScript(api *swagger.API) ([]byte, error) {
+	g := &tsGenerator{
+		api:   api,
+		types: typeNames(api, tsReserved),
+		buf:   &bytes.Buffer{},
+	}
+
+	g.printf("// Code generated by docs-gen. DO NOT EDIT.\n")
+	g.definitions()
+	g.security()
+	g.printf("%v", tsRuntime)
+	for _, op := range operations(api) {
+		g.operation(op)
+	}
+
+	return g.buf.Bytes(), nil
+}
+
+// tsReserved are the types declared by the runtime, or used by it from the global scope
+var tsReserved = []string{"ApiError", "Blob", "BodyInit", "ClientOptions", "Credentials", "Error", "FormData", "Problem", "Promise",
+	"RequestParts", "URLSearchParams", "Values"}
+
+type tsGenerator struct {
+	api   *swagger.API
+	types map[string]string
+	buf   *bytes.Buffer
+}
+
+func (g *tsGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+func (g *tsGenerator) comment(indent, text string) {
+	text = strings.Replace(strings.TrimSpace(text), "*/", "*\\/", -1)
+	g.printf("%v/** %v */\n", indent, strings.Join(strings.Fields(text), " "))
+}
+
+// deprecated writes a doc comment carrying the @deprecated tag on its own line so editors recognise it
+func (g *tsGenerator) deprecated(indent, text, note string) {
+	clean := func(v string) string {
+		v = strings.Replace(strings.TrimSpace(v), "*/", "*\\/", -1)
+		return strings.Join(strings.Fields(v), " ")
+	}
+
+	if text == "" {
+		g.printf("%v/** %v */\n", indent, strings.TrimSpace("@deprecated "+clean(note)))
+		return
+	}
+	g.printf("%v/**\n", indent)
+	g.printf("%v * %v\n", indent, clean(text))
+	g.printf("%v * %v\n", indent, strings.TrimSpace("@deprecated "+clean(note)))
+	g.printf("%v */\n", indent)
+}
+
+func (g *tsGenerator) definitions() {
+	for _, name := range definitionNames(g.api) {
+		obj := g.api.Definitions[name]
+		if implementations := polymorphic(g.api, name); len(implementations) > 0 {
+			g.printf("\n")
+			if obj.Description != "" {
+				g.comment("", obj.Description)
+			}
+			types := make([]string, 0, len(implementations))
+			for _, impl := range implementations {
+				types = append(types, g.types[impl])
+			}
+			g.printf("export type %v = %v;\n", g.types[name], strings.Join(types, " | "))
+			continue
+		}
+
+		obj = swagger.Flatten(obj, g.api.Definitions)
+		if obj.Type != "object" {
+			continue
+		}
+
+		g.printf("\n")
+		if obj.Description != "" {
+			g.comment("", obj.Description)
+		}
+		g.printf("export interface %v {\n", g.types[name])
+		for _, prop := range propertyNames(obj) {
+			p := obj.Properties[prop]
+			switch {
+			case p.Deprecated:
+				g.deprecated("  ", p.Description, "")
+			case p.Description != "":
+				g.comment("  ", p.Description)
+			}
+
+			optional := "?"
+			if isRequired(obj, prop) {
+				optional = ""
+			}
+			g.printf("  %v%v: %v;\n", tsKey(prop), optional, g.propertyType(p))
+		}
+		g.printf("}\n")
+	}
+}
+
+func (g *tsGenerator) security() {
+	g.printf("\nexport interface Credentials {\n")
+	for _, name := range schemeNames(g.api) {
+		switch g.api.SecurityDefinitions[name].Kind() {
+		case "basic":
+			g.printf("  %v?: { username: string; password: string };\n", tsKey(name))
+		case "apiKey", "bearer", "oauth2":
+			g.printf("  %v?: string;\n", tsKey(name))
+		}
+	}
+	g.printf("}\n")
+
+	g.printf("\nconst securitySchemes: { [name: string]: { type: string; in?: string; name?: string } } = {\n")
+	for _, name := range schemeNames(g.api) {
+		scheme := g.api.SecurityDefinitions[name]
+		switch typ := scheme.Kind(); typ {
+		case "apiKey":
+			g.printf("  %v: { type: %q, in: %q, name: %q },\n", tsKey(name), typ, scheme.In, scheme.Name)
+		case "basic", "bearer", "oauth2":
+			g.printf("  %v: { type: %q },\n", tsKey(name), typ)
+		}
+	}
+	g.printf("};\n")
+}
+
+func (g *tsGenerator) operation(op swagger.Operation) {
+	e := op.Endpoint
+	name := operationName(op)
+
+	var params []swagger.Parameter
+	for _, p := range e.Parameters {
+		switch p.In {
+		case "path", "query", "header", "body", "formData":
+			params = append(params, p)
+		}
+	}
+
+	signature := "options: ClientOptions"
+	if len(params) > 0 {
+		g.printf("\nexport interface %vParams {\n", name)
+		for _, p := range params {
+			if p.Description != "" {
+				g.comment("  ", p.Description)
+			}
+
+			optional := "?"
+			if p.Required || p.In == "path" {
+				optional = ""
+			}
+			g.printf("  %v%v: %v;\n", tsKey(paramKey(p)), optional, g.paramType(p))
+		}
+		g.printf("}\n")
+		signature += ", params: " + name + "Params"
+	}
+
+	returns := "void"
+	if schema := successResponse(e); schema != nil {
+		returns = g.schemaType(schema)
+	}
+
+	var query, headers, form []string
+	body := "undefined"
+	for _, p := range params {
+		value := "params" + tsAccess(paramKey(p))
+		switch p.In {
+		case "query":
+			query = append(query, strconv.Quote(p.Name)+": "+value)
+		case "header":
+			headers = append(headers, strconv.Quote(p.Name)+": "+value)
+		case "formData":
+			form = append(form, strconv.Quote(p.Name)+": "+value)
+		case "body":
+			body = value
+		}
+	}
+
+	summary := e.Summary
+	if summary == "" {
+		summary = "calls " + op.Method + " " + op.Path
+	}
+	g.printf("\n")
+	if e.Deprecated {
+		g.deprecated("", summary, strings.TrimPrefix(deprecation(e), "Deprecated: "))
+	} else {
+		g.comment("", summary)
+	}
+	g.printf("export function %v(%v): Promise<%v> {\n", unexported(name), signature, returns)
+	g.printf("  return request<%v>(options, %q, %v, {\n", returns, op.Method, tsPath(op.Path))
+	g.printf("    query: %v,\n", tsObject(query))
+	g.printf("    headers: %v,\n", tsObject(headers))
+	g.printf("    body: %v,\n", body)
+	if len(form) > 0 {
+		g.printf("    form: %v,\n", tsObject(form))
+	}
+	g.printf("    security: %v,\n", g.securityExpr(e))
+	g.printf("  });\n}\n")
+}
+
+func tsObject(fields []string) string {
+	if len(fields) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+func paramKey(p swagger.Parameter) string {
+	if p.In == "body" {
+		return "body"
+	}
+	return p.Name
+}
+
+// tsPath converts a path template into a template literal that interpolates the path parameters
+func tsPath(template string) string {
+	b := &strings.Builder{}
+	b.WriteString("`")
+	for _, segment := range strings.Split(strings.Trim(template, "/"), "/") {
+		b.WriteString("/")
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			b.WriteString("${encodeURIComponent(String(params" + tsAccess(segment[1:len(segment)-1]) + "))}")
+			continue
+		}
+		b.WriteString(segment)
+	}
+	b.WriteString("`")
+	return b.String()
+}
+
+func (g *tsGenerator) securityExpr(e *swagger.Endpoint) string {
+	alternatives := securityRequirements(g.api, e)
+	values := make([]string, 0, len(alternatives))
+	for _, names := range alternatives {
+		quoted := make([]string, 0, len(names))
+		for _, name := range names {
+			quoted = append(quoted, strconv.Quote(name))
+		}
+		values = append(values, "["+strings.Join(quoted, ", ")+"]")
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func (g *tsGenerator) paramType(p swagger.Parameter) string {
+	if p.In == "body" {
+		if p.Schema == nil {
+			return "unknown"
+		}
+		return g.schemaType(p.Schema)
+	}
+
+	if p.Type == "array" {
+		return "string[]"
+	}
+	return tsPrimitive(p.Type)
+}
+
+func (g *tsGenerator) schemaType(schema *swagger.Schema) string {
+	switch {
+	case schema.Ref != "":
+		return g.refType(schema.Ref)
+	case schema.Type == "array" && schema.Items != nil:
+		return array(g.itemsType(schema.Items))
+	default:
+		return tsPrimitive(schema.Type)
+	}
+}
+
+func (g *tsGenerator) refType(ref string) string {
+	obj, ok := g.api.Definitions[definitionName(ref)]
+	switch {
+	case !ok:
+		return "unknown"
+	case obj.Type != "object" && len(obj.AllOf) == 0:
+		return tsPrimitive(obj.Type)
+	default:
+		return g.types[definitionName(ref)]
+	}
+}
+
+func (g *tsGenerator) itemsType(items *swagger.Items) string {
+	if items.Ref != "" {
+		return g.refType(items.Ref)
+	}
+	return tsPrimitive(items.Type)
+}
+
+func (g *tsGenerator) propertyType(p swagger.Property) string {
+	switch {
+	case len(p.Enum) > 0:
+		values := make([]string, 0, len(p.Enum))
+		for _, v := range p.Enum {
+			values = append(values, strconv.Quote(v))
+		}
+		return strings.Join(values, " | ")
+	case p.Ref != "":
+		return g.refType(p.Ref)
+	case p.Type == "array" && p.Items != nil:
+		return array(g.itemsType(p.Items))
+	case p.Type == "array":
+		return "unknown[]"
+	case p.AdditionalProperties != nil:
+		return "{ [key: string]: " + g.itemsType(p.AdditionalProperties) + " }"
+	default:
+		return tsPrimitive(p.Type)
+	}
+}
+
+func array(typ string) string {
+	if strings.Contains(typ, " ") {
+		return "(" + typ + ")[]"
+	}
+	return typ + "[]"
+}
+
+func tsPrimitive(typ string) string {
+	switch typ {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "string":
+		return "string"
+	case "object":
+		return "{ [key: string]: unknown }"
+	case "file":
+		return "Blob"
+	}
+	return "unknown"
+}
+
+// tsKey quotes property names that aren't valid identifiers
+func tsKey(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')) {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+func tsAccess(name string) string {
+	if key := tsKey(name); key != name {
+		return "[" + key + "]"
+	}
+	return "." + name
+}
+
+const tsRuntime = `
+export interface ClientOptions {
+  /** scheme and host the api is served from e.g. https://api.example.com */
+  baseUrl: string;
+  credentials?: Credentials;
+  fetch?: typeof fetch;
+}
+
+/** RFC 7807 problem details response */
+export interface Problem {
+  type?: string;
+  title?: string;
+  status?: number;
+  detail?: string;
+  instance?: string;
+}
+
+/** thrown when the api responds with a status other than 2xx */
+export class ApiError extends Error {
+  constructor(public status: number, public body: string, public problem?: Problem) {
+    super(problem && problem.detail ? status + " " + problem.title + ": " + problem.detail : "status " + status);
+  }
+}
+
+type Values = { [name: string]: unknown };
+
+interface RequestParts {
+  query: Values;
+  headers: Values;
+  body: unknown;
+  /** formData parameters; sent as multipart/form-data when they include a file */
+  form?: Values;
+  security: string[][];
+}
+
+function encodeForm(form: Values): BodyInit {
+  const entries: [string, string | Blob][] = [];
+  for (const [name, value] of Object.entries(form)) {
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (v !== undefined) {
+        entries.push([name, v instanceof Blob ? v : String(v)]);
+      }
+    }
+  }
+
+  if (entries.some(([, v]) => v instanceof Blob)) {
+    // fetch sets the multipart Content-Type, including its boundary
+    const data = new FormData();
+    entries.forEach(([name, v]) => data.append(name, v));
+    return data;
+  }
+  return new URLSearchParams(entries as [string, string][]);
+}
+
+function authorize(credentials: Credentials, schemes: string[], headers: { [name: string]: string }, query: URLSearchParams): boolean {
+  const values = credentials as { [name: string]: unknown };
+  if (!schemes.every((scheme) => values[scheme] !== undefined)) {
+    return false;
+  }
+
+  for (const scheme of schemes) {
+    const definition = securitySchemes[scheme];
+    const value = values[scheme];
+    if (definition.type === "basic") {
+      const { username, password } = value as { username: string; password: string };
+      headers["Authorization"] = "Basic " + btoa(username + ":" + password);
+    } else if (definition.type === "apiKey" && definition.in === "query") {
+      query.set(definition.name as string, String(value));
+    } else if (definition.type === "apiKey" && definition.in === "cookie") {
+      // browsers manage the Cookie header themselves; this applies to server side runtimes
+      const cookie = definition.name + "=" + encodeURIComponent(String(value));
+      headers["Cookie"] = headers["Cookie"] ? headers["Cookie"] + "; " + cookie : cookie;
+    } else if (definition.type === "apiKey") {
+      headers[definition.name as string] = String(value);
+    } else {
+      headers["Authorization"] = "Bearer " + String(value);
+    }
+  }
+  return true;
+}
+
+async function request<T>(options: ClientOptions, method: string, path: string, parts: RequestParts): Promise<T> {
+  const query = new URLSearchParams();
+  for (const [name, value] of Object.entries(parts.query)) {
+    if (Array.isArray(value)) {
+      value.forEach((v) => query.append(name, String(v)));
+    } else if (value !== undefined) {
+      query.set(name, String(value));
+    }
+  }
+
+  const headers: { [name: string]: string } = { Accept: "application/json, */*;q=0.8" };
+  for (const [name, value] of Object.entries(parts.headers)) {
+    if (value !== undefined) {
+      headers[name] = String(value);
+    }
+  }
+  let body: BodyInit | undefined;
+  if (parts.form !== undefined) {
+    body = encodeForm(parts.form);
+  } else if (parts.body !== undefined) {
+    headers["Content-Type"] = "application/json";
+    body = JSON.stringify(parts.body);
+  }
+
+  for (const schemes of parts.security) {
+    if (authorize(options.credentials || {}, schemes, headers, query)) {
+      break;
+    }
+  }
+
+  const search = query.toString();
+  const url = options.baseUrl.replace(/\/$/, "") + path + (search ? "?" + search : "");
+  const response = await (options.fetch || fetch)(url, {
+    method,
+    headers,
+    body,
+  });
+
+  const contentType = response.headers.get("Content-Type") || "";
+  if (response.ok && contentType && !contentType.includes("json")) {
+    // downloads and other non-json bodies, e.g. text/csv
+    return (contentType.startsWith("text/") ? await response.text() : await response.blob()) as unknown as T;
+  }
+
+  const text = await response.text();
+  if (!response.ok) {
+    let problem: Problem | undefined;
+    if ((response.headers.get("Content-Type") || "").includes("problem+json")) {
+      try {
+        problem = JSON.parse(text) as Problem;
+      } catch (e) {
+        problem = undefined;
+      }
+    }
+    throw new ApiError(response.status, text, problem);
+  }
+
+  return (text ? JSON.parse(text) : undefined) as T;
+}
+`
@@ -0,0 +1,88 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationHeaders(t *testing.T) {
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	e := Endpoints{
+		Get: &Endpoint{
+			Deprecated:      true,
+			DeprecatedSince: time.Date(2023, time.June, 30, 23, 59, 59, 0, time.UTC),
+			Sunset:          sunset,
+			Successor:       "/v2/pets",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+		Post: &Endpoint{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets", nil))
+	assert.Equal(t, "@1688169599", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</v2/pets>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pets", nil))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Link"))
+
+	// without a date the earlier draft's form is used
+	h := http.Header{}
+	SetDeprecationHeaders(h, &Endpoint{Deprecated: true})
+	assert.Equal(t, "true", h.Get("Deprecation"))
+}
+
+func TestDeprecatedTag(t *testing.T) {
+	type Legacy struct {
+		Name     string `json:"name"`
+		Nickname string `json:"nickname" deprecated:"true"`
+	}
+
+	obj := define("", Legacy{})["swaggerLegacy"]
+	assert.False(t, obj.Properties["name"].Deprecated)
+	assert.True(t, obj.Properties["nickname"].Deprecated)
+}
+
+func TestLoadDeprecation(t *testing.T) {
+	api := &API{
+		Swagger: "2.0",
+		Paths: map[string]*Endpoints{
+			"/pets": {
+				Get: &Endpoint{
+					Deprecated: true,
+					Extensions: map[string]interface{}{
+						"x-deprecated-since": "2023-06-30T23:59:59Z",
+						"x-sunset":           "2030-01-01T00:00:00Z",
+						"x-successor":        "/v2/pets",
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(api)
+	assert.Nil(t, err)
+
+	loaded, err := Load(bytes.NewReader(data))
+	assert.Nil(t, err)
+	e := loaded.Paths["/pets"].Get
+	assert.True(t, e.Deprecated)
+	assert.Equal(t, time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC), e.Sunset)
+	assert.Equal(t, "/v2/pets", e.Successor)
+	assert.Equal(t, time.Date(2023, time.June, 30, 23, 59, 59, 0, time.UTC), e.DeprecatedSince)
+}
@@ -0,0 +1,45 @@
+package swagger
+
+import (
+	"reflect"
+	"sync"
+)
+
+var descriptions = struct {
+	sync.RWMutex
+	table map[string]string
+}{
+	table: map[string]string{},
+}
+
+// RegisterDescriptions registers descriptions for types and struct fields, typically extracted from go doc comments
+// by the godoc package.  Types are keyed by {import path}.{Type} and fields by {import path}.{Type}.{Field}.  The
+// desc struct tag takes precedence over registered descriptions
+func RegisterDescriptions(table map[string]string) {
+	descriptions.Lock()
+	defer descriptions.Unlock()
+
+	for k, v := range table {
+		descriptions.table[k] = v
+	}
+}
+
+func typeDescription(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+
+	descriptions.RLock()
+	defer descriptions.RUnlock()
+	return descriptions.table[t.PkgPath()+"."+t.Name()]
+}
+
+func fieldDescription(t reflect.Type, field string) string {
+	if t.Name() == "" {
+		return ""
+	}
+
+	descriptions.RLock()
+	defer descriptions.RUnlock()
+	return descriptions.table[t.PkgPath()+"."+t.Name()+"."+field]
+}
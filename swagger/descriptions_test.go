@@ -0,0 +1,25 @@
+package swagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Documented struct {
+	Name  string `json:"name"`
+	Color string `json:"color" desc:"from the tag"`
+}
+
+func TestRegisterDescriptions(t *testing.T) {
+	RegisterDescriptions(map[string]string{
+		"github.com/threeq/docs/swagger.Documented":       "a documented type",
+		"github.com/threeq/docs/swagger.Documented.Name":  "from the doc comment",
+		"github.com/threeq/docs/swagger.Documented.Color": "ignored",
+	})
+
+	obj := define("", Documented{})["swaggerDocumented"]
+	assert.Equal(t, "a documented type", obj.Description)
+	assert.Equal(t, "from the doc comment", obj.Properties["name"].Description)
+	assert.Equal(t, "from the tag", obj.Properties["color"].Description)
+}
@@ -0,0 +1,187 @@
+package docstest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Coverage records which documented endpoints and response codes were exercised; wrap the handler under test with
+// Handler, or pass the Coverage to Run, and inspect Report or Missing once the tests have run
+type Coverage struct {
+	api *swagger.API
+
+	mu         sync.Mutex
+	hits       map[string]map[string]int
+	undeclared map[string]map[string]int
+}
+
+// NewCoverage returns an empty Coverage of the api's endpoints
+func NewCoverage(api *swagger.API) *Coverage {
+	return &Coverage{
+		api:        api,
+		hits:       map[string]map[string]int{},
+		undeclared: map[string]map[string]int{},
+	}
+}
+
+// operationKey identifies an endpoint by its method and documented path including the basePath e.g. GET /api/pets/{id}
+func operationKey(method, urlPath string) string {
+	return method + " " + urlPath
+}
+
+// Record records a response with the status code to a request with the method and path, which includes the basePath;
+// requests that don't match a documented endpoint are ignored
+func (c *Coverage) Record(method, urlPath string, status int) {
+	api := c.api.Snapshot()
+	e, _ := api.Lookup(method, urlPath)
+	if e == nil {
+		return
+	}
+
+	key := operationKey(e.Method, path.Join(api.BasePath, e.Path))
+	code := strconv.Itoa(status)
+	hits := c.hits
+	if _, ok := e.Responses[code]; !ok {
+		if _, ok := e.Responses["default"]; ok {
+			code = "default"
+		} else {
+			hits = c.undeclared
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if hits[key] == nil {
+		hits[key] = map[string]int{}
+	}
+	hits[key][code]++
+}
+
+// Handler wraps h so the status code of every response it writes is recorded
+func (c *Coverage) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, req)
+		c.Record(req.Method, req.URL.Path, rec.status)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+type coverageRow struct {
+	Operation string
+	Code      string
+	Hits      int
+	Declared  bool
+
+	// Unspecified marks the row of an endpoint that declares no responses
+	Unspecified bool
+}
+
+// rows returns every declared response of every endpoint along with the undeclared responses that were recorded,
+// sorted by operation and then code; endpoints that declare no responses get a row of their own so they're counted
+func (c *Coverage) rows() []coverageRow {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var rows []coverageRow
+	c.api.Walk(func(urlPath string, e *swagger.Endpoint) {
+		key := operationKey(e.Method, urlPath)
+		if len(e.Responses) == 0 {
+			rows = append(rows, coverageRow{Operation: key, Code: "-", Unspecified: true})
+		}
+		for code := range e.Responses {
+			rows = append(rows, coverageRow{Operation: key, Code: code, Hits: c.hits[key][code], Declared: true})
+		}
+		for code, n := range c.undeclared[key] {
+			rows = append(rows, coverageRow{Operation: key, Code: code, Hits: n})
+		}
+	})
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Operation != rows[j].Operation {
+			return rows[i].Operation < rows[j].Operation
+		}
+		return rows[i].Code < rows[j].Code
+	})
+	return rows
+}
+
+// Missing returns the declared responses that weren't exercised e.g. GET /api/pets/{id} 404
+func (c *Coverage) Missing() []string {
+	var missing []string
+	for _, row := range c.rows() {
+		if row.Declared && row.Hits == 0 {
+			missing = append(missing, row.Operation+" "+row.Code)
+		}
+	}
+	return missing
+}
+
+// Report returns a table of every declared response and how often it was exercised, preceded by a summary of the
+// endpoints and responses covered.  Responses with codes that weren't declared are listed as undeclared
+func (c *Coverage) Report() string {
+	rows := c.rows()
+
+	var responses, covered int
+	endpoints := map[string]bool{}
+	exercised := map[string]bool{}
+	for _, row := range rows {
+		endpoints[row.Operation] = true
+		if row.Hits > 0 {
+			exercised[row.Operation] = true
+		}
+		if row.Declared {
+			responses++
+			if row.Hits > 0 {
+				covered++
+			}
+		}
+	}
+
+	table := &bytes.Buffer{}
+	w := tabwriter.NewWriter(table, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		note := ""
+		switch {
+		case row.Unspecified:
+			note = "no responses declared"
+		case !row.Declared:
+			note = "undeclared"
+		case row.Hits == 0:
+			note = "missing"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", row.Operation, row.Code, row.Hits, note)
+	}
+	w.Flush()
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "coverage: %v of %v endpoints, %v of %v responses\n", len(exercised), len(endpoints), covered, responses)
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		if line != "" {
+			buf.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,332 @@
+// Package docstest verifies that a handler honors the contract documented by a swagger.API: every endpoint is called
+// with a request built from its declared parameters and the response is checked against the declared responses
+//
+//	func TestContract(t *testing.T) {
+//		docstest.Run(t, api, router, docstest.Value("id", "1"))
+//	}
+//
+// Fuzz, which requires go1.18, goes beyond the happy path with valid and deliberately invalid requests derived from
+// the same contract
+package docstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Option provides configuration options to Run
+type Option func(c *config)
+
+type config struct {
+	values   map[string]string
+	prepare  []func(req *http.Request)
+	skip     map[string]bool
+	coverage *Coverage
+}
+
+// Value sets the value of every parameter with the specified name, e.g. an id that exists, in place of one synthesized
+// from the parameter's type; optional query and header parameters with a value are sent as well
+func Value(name, value string) Option {
+	return func(c *config) {
+		c.values[name] = value
+	}
+}
+
+// Prepare registers a function that modifies each request before it is sent e.g. to add credentials
+func Prepare(fn func(req *http.Request)) Option {
+	return func(c *config) {
+		c.prepare = append(c.prepare, fn)
+	}
+}
+
+// Skip excludes the endpoint with the specified method and path, including the basePath e.g. GET /api/pets/{id}
+func Skip(method, path string) Option {
+	return func(c *config) {
+		c.skip[operationKey(strings.ToUpper(method), path)] = true
+	}
+}
+
+// RecordCoverage records the responses received by Run in c
+func RecordCoverage(c *Coverage) Option {
+	return func(cfg *config) {
+		cfg.coverage = c
+	}
+}
+
+// Run calls the handler once for every endpoint of the api, each in a subtest named after the method and path, and
+// fails the subtest if the response code isn't declared or the body doesn't conform to the declared schema; endpoints
+// that declare no responses must respond with a 2xx.  Requests are built from the declared parameters: path and
+// required parameters are synthesized from their types and the body from its schema unless an example is declared
+func Run(t *testing.T, api *swagger.API, handler http.Handler, options ...Option) {
+	c := newConfig(options)
+	api = api.Snapshot()
+
+	for _, op := range operations(api, c) {
+		op := op
+		t.Run(operationKey(op.Endpoint.Method, op.Path), func(t *testing.T) {
+			for _, problem := range check(api, handler, op, c) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func newConfig(options []Option) *config {
+	c := &config{
+		values: map[string]string{},
+		skip:   map[string]bool{},
+	}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// operations returns the endpoints of the api that aren't skipped in the order of swagger.API.Operations, so they're
+// exercised in a stable order; their paths include the basePath
+func operations(api *swagger.API, c *config) []swagger.Operation {
+	var ops []swagger.Operation
+	for _, op := range api.Operations() {
+		op.Path = path.Join(api.BasePath, op.Path)
+		if !c.skip[operationKey(op.Method, op.Path)] {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// check calls the handler for the operation and returns the ways the response violates the documented contract
+func check(api *swagger.API, handler http.Handler, op swagger.Operation, c *config) []string {
+	req, err := newRequest(api, op, c)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	return verify(api, op, req, serve(handler, req, c))
+}
+
+// serve calls the handler with the request and records the response in the coverage, if any
+func serve(handler http.Handler, req *http.Request, c *config) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if c.coverage != nil {
+		c.coverage.Record(req.Method, req.URL.Path, w.Code)
+	}
+	return w
+}
+
+// verify returns the ways the response to the request violates the contract documented for the operation
+func verify(api *swagger.API, op swagger.Operation, req *http.Request, w *httptest.ResponseRecorder) []string {
+	e := op.Endpoint
+	if len(e.Responses) == 0 {
+		if w.Code < 200 || w.Code >= 300 {
+			return []string{fmt.Sprintf("%v %v returned %v, but the endpoint declares no responses so only a 2xx is acceptable; body: %v", req.Method, req.URL, w.Code, truncate(w.Body.String()))}
+		}
+		return nil
+	}
+
+	response, ok := e.Responses[strconv.Itoa(w.Code)]
+	if !ok {
+		response, ok = e.Responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("%v %v returned %v, which isn't a declared response; body: %v", req.Method, req.URL, w.Code, truncate(w.Body.String()))}
+	}
+
+	schema := response.Schema
+	if schema == nil || schema.Type == "file" || w.Code == http.StatusNoContent {
+		return nil
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return nil
+	}
+	if w.Body.Len() == 0 {
+		return []string{fmt.Sprintf("%v %v returned %v with an empty body; expected a document", req.Method, req.URL, w.Code)}
+	}
+
+	if err := Validate(schema, api.Definitions, w.Body.Bytes()); err != nil {
+		return []string{fmt.Sprintf("%v %v returned %v with a body that doesn't conform to the schema: %v", req.Method, req.URL, w.Code, err)}
+	}
+	return nil
+}
+
+// newRequest builds a request to the operation from the endpoint's declared parameters
+func newRequest(api *swagger.API, op swagger.Operation, c *config) (*http.Request, error) {
+	return newDraft(api, op, c).request(op, c)
+}
+
+// draft is a request to an operation that hasn't been encoded yet, so its parameters and body can still be altered
+type draft struct {
+	Params      []param
+	HasBody     bool
+	Schema      *swagger.Schema
+	Body        interface{}
+	ContentType string
+
+	// Raw, when set, is sent as the body in place of Body
+	Raw []byte
+}
+
+type param struct {
+	swagger.Parameter
+	Value string
+	Send  bool
+}
+
+// newDraft returns a draft of a valid request to the operation; path and required parameters are synthesized from
+// their types and the body from its schema unless an example is declared
+func newDraft(api *swagger.API, op swagger.Operation, c *config) *draft {
+	e := op.Endpoint
+	d := &draft{}
+	for _, p := range e.Parameters {
+		if p.In == "body" {
+			d.HasBody, d.Schema = true, p.Schema
+			d.Body, d.ContentType = bodyOf(api, e, p)
+			if s, ok := d.Body.(string); ok && !strings.Contains(d.ContentType, "json") {
+				d.Raw = []byte(s)
+			}
+			continue
+		}
+
+		v, ok := c.values[p.Name]
+		if !ok {
+			v = example(p)
+		}
+		d.Params = append(d.Params, param{Parameter: p, Value: v, Send: ok || p.Required || p.In == "path"})
+	}
+	return d
+}
+
+// request encodes the draft as a request to the operation
+func (d *draft) request(op swagger.Operation, c *config) (*http.Request, error) {
+	e := op.Endpoint
+	target := op.Path
+	query := url.Values{}
+	headers := http.Header{}
+	form := url.Values{}
+	var files []string
+	for _, p := range d.Params {
+		if !p.Send {
+			continue
+		}
+
+		switch p.In {
+		case "path":
+			target = strings.Replace(target, "{"+p.Name+"}", url.PathEscape(p.Value), -1)
+		case "query":
+			query.Set(p.Name, p.Value)
+		case "header":
+			headers.Set(p.Name, p.Value)
+		case "formData":
+			if p.Type == "file" {
+				files = append(files, p.Name)
+				continue
+			}
+			form.Set(p.Name, p.Value)
+		}
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body []byte
+	contentType := ""
+	switch {
+	case d.Raw != nil:
+		body, contentType = d.Raw, d.ContentType
+
+	case d.HasBody:
+		data, err := json.Marshal(d.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode the example body of %v %v: %v", e.Method, e.Path, err)
+		}
+		body, contentType = data, d.ContentType
+
+	case len(files) > 0:
+		buf := &bytes.Buffer{}
+		mw := multipart.NewWriter(buf)
+		for name := range form {
+			mw.WriteField(name, form.Get(name))
+		}
+		for _, name := range files {
+			part, err := mw.CreateFormFile(name, name)
+			if err != nil {
+				return nil, err
+			}
+			part.Write([]byte("docstest"))
+		}
+		mw.Close()
+		body, contentType = buf.Bytes(), mw.FormDataContentType()
+
+	case len(form) > 0:
+		body, contentType = []byte(form.Encode()), "application/x-www-form-urlencoded"
+	}
+
+	req := httptest.NewRequest(e.Method, target, bytes.NewReader(body))
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if len(e.Produces) > 0 {
+		req.Header.Set("Accept", strings.Join(e.Produces, ", "))
+	}
+
+	for _, fn := range c.prepare {
+		fn(req)
+	}
+
+	return req, nil
+}
+
+// bodyOf returns the example of the body parameter of the endpoint and its content type; an example declared for the
+// content type takes precedence over one synthesized from the schema
+func bodyOf(api *swagger.API, e *swagger.Endpoint, p swagger.Parameter) (interface{}, string) {
+	contentType := "application/json"
+	if len(e.Consumes) > 0 {
+		contentType = e.Consumes[0]
+	}
+
+	v, ok := p.Examples[contentType]
+	if !ok {
+		v = swagger.SchemaExample(p.Schema, api.Definitions)
+	}
+	return v, contentType
+}
+
+// example returns a value for the non-body parameter, its first enum value or one synthesized from its type
+func example(p swagger.Parameter) string {
+	if len(p.Enum) > 0 {
+		return p.Enum[0]
+	}
+
+	v := swagger.Example(swagger.Object{Type: p.Type, Format: p.Format}, nil)
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// truncate shortens long response bodies in failure messages
+func truncate(v string) string {
+	const max = 200
+	if len(v) > max {
+		return v[:max] + "..."
+	}
+	return v
+}
@@ -0,0 +1,352 @@
+package docstest
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+)
+
+type Pet struct {
+	ID     int64    `json:"id" required:"true"`
+	Name   string   `json:"name" required:"true" maxLength:"64"`
+	Status string   `json:"status,omitempty" enum:"available,sold"`
+	Tags   []string `json:"tags"`
+	Owner  *Owner   `json:"owner"`
+}
+
+type Owner struct {
+	Name string `json:"name" required:"true"`
+}
+
+type Event interface {
+	event()
+}
+
+type Created struct {
+	Pet Pet `json:"pet"`
+}
+
+func (Created) event() {}
+
+type Deleted struct {
+	ID int64 `json:"id" required:"true"`
+}
+
+func (Deleted) event() {}
+
+func init() {
+	swagger.RegisterPolymorphic((*Event)(nil), "type",
+		swagger.Implements("created", Created{}),
+		swagger.Implements("deleted", Deleted{}),
+	)
+}
+
+func petstore() *swagger.API {
+	api := &swagger.API{BasePath: "/api"}
+	api.AddEndpoint(endpoint.Get("/pets", "list pets",
+		endpoint.Query("status", "string", "status filter", true),
+		endpoint.Enum("status", "available", "sold"),
+		endpoint.Query("limit", "integer", "maximum number of pets", false),
+		endpoint.Minimum("limit", 1),
+		endpoint.Maximum("limit", 100),
+		endpoint.Response(http.StatusOK, []Pet{}, "", "the pets"),
+		endpoint.Response(http.StatusBadRequest, nil, "", "missing status"),
+	))
+	api.AddEndpoint(endpoint.Post("/pets", "create a pet",
+		endpoint.Body(Pet{}, "the pet", true),
+		endpoint.Response(http.StatusCreated, Pet{}, "", "created"),
+		endpoint.Response(http.StatusBadRequest, nil, "", "invalid pet"),
+	))
+	api.AddEndpoint(endpoint.Get("/pets/{id}", "find a pet",
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusOK, Pet{}, "", "the pet"),
+		endpoint.Response(http.StatusNotFound, nil, "", "not found"),
+	))
+	return api
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// petHandler honors the documented contract
+func petHandler(w http.ResponseWriter, req *http.Request) {
+	rex := Pet{ID: 1, Name: "Rex", Status: "available"}
+
+	switch {
+	case req.Method == "GET" && req.URL.Path == "/api/pets":
+		if status := req.URL.Query().Get("status"); status != "available" && status != "sold" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, []Pet{rex})
+
+	case req.Method == "POST" && req.URL.Path == "/api/pets":
+		pet := Pet{}
+		err := json.NewDecoder(req.Body).Decode(&pet)
+		if err != nil || len(pet.Name) > 64 || (pet.Status != "" && pet.Status != "available" && pet.Status != "sold") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusCreated, pet)
+
+	case req.Method == "GET" && req.URL.Path == "/api/pets/1":
+		writeJSON(w, http.StatusOK, rex)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRun(t *testing.T) {
+	api := petstore()
+	coverage := NewCoverage(api)
+
+	Run(t, api, http.HandlerFunc(petHandler), Value("id", "1"), RecordCoverage(coverage))
+
+	assert.Equal(t, []string{
+		"GET /api/pets 400",
+		"GET /api/pets/{id} 404",
+		"POST /api/pets 400",
+	}, coverage.Missing())
+}
+
+func lookup(api *swagger.API, method, urlPath string) swagger.Operation {
+	e, _ := api.Lookup(method, urlPath)
+	return swagger.Operation{Method: method, Path: urlPath, Endpoint: e}
+}
+
+func TestCheck(t *testing.T) {
+	api := petstore().Snapshot()
+	c := newConfig(nil)
+	op := func(method, urlPath string) swagger.Operation {
+		return lookup(api, method, urlPath)
+	}
+
+	broken := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == "POST" {
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"id": "1", "name": "Rex", "status": "lost"})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, "boom")
+	})
+
+	problems := check(api, broken, op("GET", "/api/pets"), c)
+	assert.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "returned 500, which isn't a declared response; body: boom")
+
+	problems = check(api, broken, op("POST", "/api/pets"), c)
+	assert.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "$.id: expected integer, got string")
+	assert.Contains(t, problems[0], "$.status: expected one of available, sold, got lost")
+
+	// the synthesized request satisfies the declared parameters
+	var received *http.Request
+	spy := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received = req
+		w.WriteHeader(http.StatusNotFound)
+	})
+	assert.Empty(t, check(api, spy, op("GET", "/api/pets/{id}"), c))
+	assert.Equal(t, "/api/pets/0", received.URL.Path)
+
+	c.prepare = append(c.prepare, func(req *http.Request) { req.Header.Set("Authorization", "Bearer token") })
+	check(api, spy, op("GET", "/api/pets"), c)
+	assert.Equal(t, "status=available", received.URL.RawQuery)
+	assert.Equal(t, "Bearer token", received.Header.Get("Authorization"))
+
+	// endpoints that declare no responses may only succeed
+	health := &swagger.Endpoint{Method: "GET", Path: "/health"}
+	problems = check(api, broken, swagger.Operation{Method: "GET", Path: "/api/health", Endpoint: health}, c)
+	assert.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "returned 500, but the endpoint declares no responses so only a 2xx is acceptable")
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	assert.Empty(t, check(api, ok, swagger.Operation{Method: "GET", Path: "/api/health", Endpoint: health}, c))
+}
+
+func TestValidate(t *testing.T) {
+	api := &swagger.API{}
+	api.AddEndpoint(endpoint.Get("/pets", "list pets", endpoint.Response(http.StatusOK, []Pet{}, "", "")))
+	api.AddEndpoint(endpoint.Get("/events", "list events", endpoint.Response(http.StatusOK, []Event{}, "", "")))
+	pets := api.Paths["/pets"].Get.Responses["200"].Schema
+	events := api.Paths["/events"].Get.Responses["200"].Schema
+
+	api.Definitions["stamp"] = swagger.Object{
+		Type:       "object",
+		Properties: map[string]swagger.Property{"at": {Type: "string", Format: "date-time"}},
+	}
+	stamp := &swagger.Schema{Ref: "#/definitions/stamp"}
+
+	testCases := map[string]struct {
+		Schema *swagger.Schema
+		Body   string
+		Errors []string
+	}{
+		"valid": {
+			Schema: pets,
+			Body:   `[{"id": 1, "name": "Rex", "tags": ["a"], "owner": null, "extra": true}]`,
+		},
+		"not an array": {
+			Schema: pets,
+			Body:   `{}`,
+			Errors: []string{"$: expected array, got object"},
+		},
+		"required": {
+			Schema: pets,
+			Body:   `[{"id": 1}]`,
+			Errors: []string{"$[0].name: required"},
+		},
+		"nested": {
+			Schema: pets,
+			Body:   `[{"id": 1.5, "name": "Rex", "tags": [1], "owner": {"name": 2}}]`,
+			Errors: []string{"$[0].id: expected integer, got 1.5", "$[0].tags[0]: expected string, got number", "$[0].owner.name: expected string, got number"},
+		},
+		"format": {
+			Schema: stamp,
+			Body:   `{"at": "yesterday"}`,
+			Errors: []string{`$.at: expected date-time, got "yesterday"`},
+		},
+		"valid format": {
+			Schema: stamp,
+			Body:   `{"at": "2020-01-01T00:00:00Z"}`,
+		},
+		"polymorphic": {
+			Schema: events,
+			Body:   `[{"type": "deleted", "id": 1}, {"type": "created", "pet": {"id": 1, "name": "Rex"}}]`,
+		},
+		"implementation properties": {
+			Schema: events,
+			Body:   `[{"type": "deleted"}]`,
+			Errors: []string{"$[0].id: required"},
+		},
+		"unknown implementation": {
+			Schema: events,
+			Body:   `[{"type": "updated"}]`,
+			Errors: []string{`$[0]: unknown type, "updated"`},
+		},
+		"bounds": {
+			Schema: pets,
+			Body:   `[{"id": 1, "name": "` + strings.Repeat("a", 65) + `"}]`,
+			Errors: []string{"$[0].name: expected at most 64 characters, got 65"},
+		},
+		"primitive": {
+			Schema: &swagger.Schema{Type: "string"},
+			Body:   `42`,
+			Errors: []string{"$: expected string, got number"},
+		},
+		"invalid json": {
+			Schema: pets,
+			Body:   `[`,
+			Errors: []string{"invalid json: unexpected EOF"},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			err := Validate(tc.Schema, api.Definitions, []byte(tc.Body))
+			if len(tc.Errors) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.NotNil(t, err)
+			for _, want := range tc.Errors {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	api := petstore()
+	api.AddEndpoint(endpoint.Get("/health", "report the health of the service"))
+	coverage := NewCoverage(api)
+	h := coverage.Handler(http.HandlerFunc(petHandler))
+
+	for _, target := range []string{"/api/pets?status=sold", "/api/pets/1", "/api/pets/1", "/api/pets/2", "/api/unknown"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/pets", strings.NewReader("{")))
+	coverage.Record("POST", "/api/pets", http.StatusInternalServerError)
+
+	assert.Equal(t, []string{"GET /api/pets 400", "POST /api/pets 201"}, coverage.Missing())
+
+	report := coverage.Report()
+	assert.Contains(t, report, "coverage: 3 of 4 endpoints, 4 of 6 responses\n")
+	assert.Contains(t, report, "GET /api/health     -    0  no responses declared\n")
+	assert.Contains(t, report, "GET /api/pets/{id}  200  2\n")
+	assert.Contains(t, report, "POST /api/pets      500  1  undeclared\n")
+	assert.Contains(t, report, "GET /api/pets       400  0  missing\n")
+}
+
+func TestMutate(t *testing.T) {
+	api := petstore().Snapshot()
+	c := newConfig([]Option{Value("id", "1")})
+	r := rand.New(rand.NewSource(1))
+
+	post := lookup(api, "POST", "/api/pets")
+	schema := post.Endpoint.Parameters[0].Schema
+	testCases := map[mutation]string{
+		missingRequired: "without the body property ",
+		wrongType:       "with the body property ",
+		oversized:       "set to a string of 65536 characters",
+		unknownEnum:     `with the body property status set to "unknown", which isn't one of available, sold`,
+	}
+	for m, description := range testCases {
+		d := newDraft(api, post, c)
+		assert.Contains(t, d.mutate(m, api.Definitions, r, nil), description)
+
+		req, err := d.request(post, c)
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.NotNil(t, Validate(schema, api.Definitions, body), "mutation %v", m)
+	}
+
+	d := newDraft(api, post, c)
+	assert.Equal(t, "with a valid request", d.mutate(valid, api.Definitions, r, nil))
+	req, _ := d.request(post, c)
+	body, _ := ioutil.ReadAll(req.Body)
+	assert.Nil(t, Validate(schema, api.Definitions, body))
+
+	d = newDraft(api, post, c)
+	assert.Equal(t, `with the body "{"`, d.mutate(garbage, api.Definitions, r, []byte("{")))
+	assert.Equal(t, []byte("{"), d.Raw)
+
+	list := lookup(api, "GET", "/api/pets")
+	for m, query := range map[mutation]string{
+		missingRequired: "",
+		wrongType:       "limit=not-an-integer&status=available",
+		unknownEnum:     "status=unknown",
+	} {
+		d := newDraft(api, list, c)
+		d.mutate(m, api.Definitions, r, nil)
+		req, err := d.request(list, c)
+		assert.Nil(t, err)
+		assert.Equal(t, query, req.URL.RawQuery)
+	}
+
+	// mutations that don't apply leave the request valid
+	find := lookup(api, "GET", "/api/pets/{id}")
+	assert.Equal(t, "with a valid request", newDraft(api, find, c).mutate(missingRequired, api.Definitions, r, nil))
+}
+
+func TestBoundaryValues(t *testing.T) {
+	min, max, maxLength := 1.0, 100.0, 2
+	assert.Equal(t, []interface{}{int64(1), int64(0), int64(100), int64(101)}, boundaryValues(constraints{Type: "integer", Minimum: &min, Maximum: &max}))
+	assert.Equal(t, []interface{}{1.0, 0.0}, boundaryValues(constraints{Type: "number", Minimum: &min}))
+	assert.Equal(t, []interface{}{"aa", "aaa"}, boundaryValues(constraints{Type: "string", MaxLength: &maxLength}))
+	assert.Equal(t, []interface{}{""}, boundaryValues(constraints{Type: "string"}))
+	assert.Len(t, boundaryValues(constraints{Type: "integer"}), 4)
+	assert.Empty(t, boundaryValues(constraints{Type: "string", Enum: []string{"a"}}))
+}
@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package docstest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Fuzz fuzzes the handler with requests generated from the api's endpoints.  Each input selects an endpoint and a
+// mutation of a valid request: a missing required parameter or body property, a value of the wrong type, a value at
+// or beyond its bounds, an oversized string, an unknown enum value or the fuzzer's bytes as a parameter or the body.
+// Responses with a 5xx code, a code that isn't declared or a body that doesn't conform to the declared schema fail
+// the input.  The seed corpus holds every mutation of every endpoint, so go test exercises each of them once
+//
+//	func FuzzContract(f *testing.F) {
+//		docstest.Fuzz(f, api, router, docstest.Value("id", "1"))
+//	}
+func Fuzz(f *testing.F, api *swagger.API, handler http.Handler, options ...Option) {
+	c := newConfig(options)
+	api = api.Snapshot()
+
+	ops := operations(api, c)
+	if len(ops) == 0 {
+		f.Skip("no endpoints to fuzz")
+	}
+
+	for i := range ops {
+		for m := valid; m < mutationCount; m++ {
+			f.Add(uint(i), uint8(m), int64(i), []byte("{}"))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, index uint, kind uint8, seed int64, data []byte) {
+		op := ops[index%uint(len(ops))]
+		d := newDraft(api, op, c)
+		description := d.mutate(mutation(kind%uint8(mutationCount)), api.Definitions, rand.New(rand.NewSource(seed)), data)
+		label := fmt.Sprintf("%v %v %v", op.Endpoint.Method, op.Path, description)
+
+		req, err := d.request(op, c)
+		if err != nil {
+			t.Skipf("%v: %v", label, err)
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("%v: handler panicked: %v", label, r)
+			}
+		}()
+
+		w := serve(handler, req, c)
+		if w.Code >= http.StatusInternalServerError {
+			t.Fatalf("%v: returned %v; body: %v", label, w.Code, truncate(w.Body.String()))
+		}
+		for _, problem := range verify(api, op, req, w) {
+			t.Errorf("%v: %v", label, problem)
+		}
+	})
+}
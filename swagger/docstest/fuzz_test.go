@@ -0,0 +1,13 @@
+//go:build go1.18
+// +build go1.18
+
+package docstest
+
+import (
+	"net/http"
+	"testing"
+)
+
+func FuzzPetstore(f *testing.F) {
+	Fuzz(f, petstore(), http.HandlerFunc(petHandler), Value("id", "1"))
+}
@@ -0,0 +1,278 @@
+package docstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// mutation is a way in which a generated request departs from the documented contract
+type mutation uint8
+
+const (
+	valid mutation = iota
+	missingRequired
+	wrongType
+	boundary
+	oversized
+	unknownEnum
+	garbage
+	mutationCount
+)
+
+// oversizedLength is the length of the strings sent by the oversized mutation
+const oversizedLength = 64 << 10
+
+// target is a parameter, or a property of the body, that a mutation can alter
+type target struct {
+	Label       string
+	Param       bool
+	Required    bool
+	Constraints constraints
+
+	set   func(v interface{})
+	unset func()
+}
+
+// targets returns the parameters of the draft followed by the top level properties of its body, sorted by name
+func (d *draft) targets(definitions map[string]swagger.Object) []target {
+	var targets []target
+	for i := range d.Params {
+		p := &d.Params[i]
+		if p.Type == "file" {
+			continue
+		}
+		targets = append(targets, target{
+			Label:    fmt.Sprintf("%v parameter %v", p.In, p.Name),
+			Param:    true,
+			Required: p.Required && p.In != "path",
+			Constraints: constraints{
+				Type:      p.Type,
+				Format:    p.Format,
+				Enum:      p.Enum,
+				Minimum:   p.Minimum,
+				Maximum:   p.Maximum,
+				MinLength: p.MinLength,
+				MaxLength: p.MaxLength,
+			},
+			set:   func(v interface{}) { p.Value, p.Send = fmt.Sprint(v), true },
+			unset: func() { p.Send = false },
+		})
+	}
+
+	body, ok := d.Body.(map[string]interface{})
+	if !ok || d.Raw != nil || d.Schema == nil || d.Schema.Ref == "" {
+		return targets
+	}
+	obj, ok := definitions[strings.TrimPrefix(d.Schema.Ref, "#/definitions/")]
+	if !ok {
+		return targets
+	}
+	obj = swagger.Flatten(obj, definitions)
+
+	var names []string
+	for name := range obj.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		name := name
+		targets = append(targets, target{
+			Label:       "body property " + name,
+			Required:    containsString(obj.Required, name),
+			Constraints: propertyConstraints(obj.Properties[name]),
+			set:         func(v interface{}) { body[name] = v },
+			unset:       func() { delete(body, name) },
+		})
+	}
+	return targets
+}
+
+// mutate alters the draft, which starts out valid, according to m and returns a description of the change; mutations
+// that don't apply to any of the draft's parameters or properties leave it valid.  Random choices are made with r and
+// the garbage mutation sends data
+func (d *draft) mutate(m mutation, definitions map[string]swagger.Object, r *rand.Rand, data []byte) string {
+	if d.HasBody && d.Raw == nil {
+		d.Body = normalize(d.Body)
+	}
+
+	targets := d.targets(definitions)
+	pick := func(ok func(t target) bool) (target, bool) {
+		var candidates []target
+		for _, t := range targets {
+			if ok(t) {
+				candidates = append(candidates, t)
+			}
+		}
+		if len(candidates) == 0 {
+			return target{}, false
+		}
+		return candidates[r.Intn(len(candidates))], true
+	}
+
+	switch m {
+	case missingRequired:
+		if t, ok := pick(func(t target) bool { return t.Required }); ok {
+			t.unset()
+			return "without the " + t.Label
+		}
+
+	case wrongType:
+		if t, ok := pick(func(t target) bool { return wrongValue(t) != nil }); ok {
+			v := wrongValue(t)
+			t.set(v)
+			return fmt.Sprintf("with the %v set to %v", t.Label, describe(v))
+		}
+
+	case boundary:
+		if t, ok := pick(func(t target) bool { return len(boundaryValues(t.Constraints)) > 0 }); ok {
+			values := boundaryValues(t.Constraints)
+			v := values[r.Intn(len(values))]
+			t.set(v)
+			return fmt.Sprintf("with the %v set to %v", t.Label, describe(v))
+		}
+
+	case oversized:
+		if t, ok := pick(func(t target) bool { return t.Constraints.Type == "string" }); ok {
+			t.set(strings.Repeat("a", oversizedLength))
+			return fmt.Sprintf("with the %v set to a string of %v characters", t.Label, oversizedLength)
+		}
+
+	case unknownEnum:
+		if t, ok := pick(func(t target) bool { return len(t.Constraints.Enum) > 0 }); ok {
+			v := unknownValue(t.Constraints.Enum)
+			t.set(v)
+			return fmt.Sprintf("with the %v set to %q, which isn't one of %v", t.Label, v, strings.Join(t.Constraints.Enum, ", "))
+		}
+
+	case garbage:
+		t, ok := pick(func(t target) bool { return t.Param })
+		if d.HasBody && (!ok || r.Intn(2) == 0) {
+			d.Raw = data
+			return fmt.Sprintf("with the body %q", truncate(string(data)))
+		}
+		if ok {
+			t.set(string(data))
+			return fmt.Sprintf("with the %v set to %q", t.Label, truncate(string(data)))
+		}
+	}
+
+	return "with a valid request"
+}
+
+// normalize returns the json representation of v, so examples built from structs can be altered like decoded ones
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+// wrongValue returns a value that isn't of the target's type, or nil if every value the target can be sent as would be
+// accepted, as is the case for string parameters
+func wrongValue(t target) interface{} {
+	switch t.Constraints.Type {
+	case "integer":
+		if t.Param {
+			return "not-an-integer"
+		}
+		return 1.5
+	case "number":
+		return "not-a-number"
+	case "boolean":
+		return "not-a-boolean"
+	case "string":
+		if t.Param {
+			return nil
+		}
+		return 42
+	case "array":
+		if t.Param {
+			return nil
+		}
+		return "not-an-array"
+	}
+	if t.Param {
+		return nil
+	}
+	return "not-an-object"
+}
+
+// boundaryValues returns the values at and just beyond the declared bounds; integers without bounds get the extremes
+// of int64 and strings without bounds get the empty string
+func boundaryValues(c constraints) []interface{} {
+	var values []interface{}
+	switch c.Type {
+	case "integer":
+		if c.Minimum == nil && c.Maximum == nil {
+			return []interface{}{int64(math.MinInt64), int64(-1), int64(0), int64(math.MaxInt64)}
+		}
+		if c.Minimum != nil {
+			values = append(values, int64(*c.Minimum), int64(*c.Minimum)-1)
+		}
+		if c.Maximum != nil {
+			values = append(values, int64(*c.Maximum), int64(*c.Maximum)+1)
+		}
+
+	case "number":
+		if c.Minimum == nil && c.Maximum == nil {
+			return []interface{}{-math.MaxFloat64, 0.0, math.MaxFloat64}
+		}
+		if c.Minimum != nil {
+			values = append(values, *c.Minimum, *c.Minimum-1)
+		}
+		if c.Maximum != nil {
+			values = append(values, *c.Maximum, *c.Maximum+1)
+		}
+
+	case "string":
+		if len(c.Enum) > 0 || c.Format != "" {
+			return nil
+		}
+		if c.MinLength == nil && c.MaxLength == nil {
+			return []interface{}{""}
+		}
+		if c.MinLength != nil {
+			values = append(values, strings.Repeat("a", *c.MinLength))
+			if *c.MinLength > 0 {
+				values = append(values, strings.Repeat("a", *c.MinLength-1))
+			}
+		}
+		if c.MaxLength != nil {
+			values = append(values, strings.Repeat("a", *c.MaxLength), strings.Repeat("a", *c.MaxLength+1))
+		}
+	}
+	return values
+}
+
+// unknownValue returns a value that isn't one of enum
+func unknownValue(enum []string) string {
+	v := "unknown"
+	for containsString(enum, v) {
+		v += "_"
+	}
+	return v
+}
+
+// describe formats a value for the description of a mutation, abbreviating long strings
+func describe(v interface{}) string {
+	if s, ok := v.(string); ok {
+		if len(s) > 16 {
+			return fmt.Sprintf("a string of %v characters", len(s))
+		}
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
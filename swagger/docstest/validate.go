@@ -0,0 +1,301 @@
+package docstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Validate checks that the json document data conforms to the schema; definitions are used to resolve $ref.  Types,
+// formats, enums, bounds, required properties and the discriminators of polymorphic definitions are checked; properties that
+// aren't declared are allowed
+func Validate(schema *swagger.Schema, definitions map[string]swagger.Object, data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+
+	s := validator{definitions: definitions}
+	s.schema("$", schema, v)
+	if len(s.errs) > 0 {
+		return fmt.Errorf("%v", strings.Join(s.errs, "; "))
+	}
+	return nil
+}
+
+type validator struct {
+	definitions map[string]swagger.Object
+	errs        []string
+}
+
+func (s *validator) errorf(path, format string, args ...interface{}) {
+	s.errs = append(s.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (s *validator) schema(path string, schema *swagger.Schema, v interface{}) {
+	switch {
+	case schema == nil:
+	case schema.Ref != "":
+		s.ref(path, schema.Ref, v)
+	case schema.Type == "array":
+		s.array(path, schema.Items, v)
+	default:
+		s.primitive(path, constraints{Type: schema.Type}, v)
+	}
+}
+
+func (s *validator) ref(path, ref string, v interface{}) {
+	obj, ok := s.definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if !ok {
+		return
+	}
+	s.object(path, obj, v)
+}
+
+// implementation returns the implementation of the polymorphic definition selected by the discriminator of v
+func (s *validator) implementation(path string, obj swagger.Object, v map[string]interface{}) (swagger.Object, bool) {
+	value, _ := v[obj.Discriminator].(string)
+	for _, name := range swagger.Implementations(s.definitions, obj.Name) {
+		impl := s.definitions[name]
+		if impl.Extensions["x-discriminator-value"] == value {
+			return impl, true
+		}
+	}
+
+	s.errorf(path, "unknown %v, %q", obj.Discriminator, value)
+	return obj, false
+}
+
+func (s *validator) object(path string, obj swagger.Object, v interface{}) {
+	if v == nil {
+		return
+	}
+
+	m, isMap := v.(map[string]interface{})
+	if obj.Discriminator != "" && isMap {
+		impl, ok := s.implementation(path, obj, m)
+		if !ok {
+			return
+		}
+		obj = impl
+	}
+	obj = swagger.Flatten(obj, s.definitions)
+
+	if obj.Type != "" && obj.Type != "object" {
+		s.primitive(path, constraints{Type: obj.Type, Format: obj.Format}, v)
+		return
+	}
+	if !isMap {
+		s.errorf(path, "expected object, got %v", kind(v))
+		return
+	}
+
+	for _, name := range obj.Required {
+		if m[name] == nil {
+			s.errorf(path+"."+name, "required")
+		}
+	}
+
+	for name, value := range m {
+		if p, ok := obj.Properties[name]; ok {
+			s.property(path+"."+name, p, value)
+		}
+	}
+}
+
+func (s *validator) property(path string, p swagger.Property, v interface{}) {
+	switch {
+	case v == nil:
+	case p.Ref != "":
+		s.ref(path, p.Ref, v)
+	case p.Type == "array":
+		s.array(path, p.Items, v)
+	case p.AdditionalProperties != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			s.errorf(path, "expected object, got %v", kind(v))
+			return
+		}
+		for key, value := range m {
+			s.items(path+"."+key, p.AdditionalProperties, value)
+		}
+	default:
+		s.primitive(path, propertyConstraints(p), v)
+	}
+}
+
+func (s *validator) array(path string, items *swagger.Items, v interface{}) {
+	if v == nil {
+		return
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		s.errorf(path, "expected array, got %v", kind(v))
+		return
+	}
+	if items == nil {
+		return
+	}
+	for i, value := range values {
+		s.items(path+"["+strconv.Itoa(i)+"]", items, value)
+	}
+}
+
+func (s *validator) items(path string, items *swagger.Items, v interface{}) {
+	switch {
+	case v == nil:
+	case items.Ref != "":
+		s.ref(path, items.Ref, v)
+	default:
+		s.primitive(path, constraints{Type: items.Type, Format: items.Format}, v)
+	}
+}
+
+// constraints are the checks that apply to a primitive value
+type constraints struct {
+	Type      string
+	Format    string
+	Enum      []string
+	Minimum   *float64
+	Maximum   *float64
+	MinLength *int
+	MaxLength *int
+}
+
+func propertyConstraints(p swagger.Property) constraints {
+	return constraints{
+		Type:      p.Type,
+		Format:    p.Format,
+		Enum:      p.Enum,
+		Minimum:   p.Minimum,
+		Maximum:   p.Maximum,
+		MinLength: p.MinLength,
+		MaxLength: p.MaxLength,
+	}
+}
+
+func (s *validator) primitive(path string, c constraints, v interface{}) {
+	if v == nil {
+		return
+	}
+
+	switch c.Type {
+	case "string":
+		value, ok := v.(string)
+		if !ok {
+			s.errorf(path, "expected string, got %v", kind(v))
+			return
+		}
+		if err := validateFormat(c.Format, value); err != nil {
+			s.errorf(path, "%v", err)
+		}
+		s.length(path, c, utf8.RuneCountInString(value))
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			s.errorf(path, "expected integer, got %v", kind(v))
+			return
+		}
+		if _, err := n.Int64(); err != nil {
+			s.errorf(path, "expected integer, got %v", n)
+			return
+		}
+		s.bounds(path, c, n)
+
+	case "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			s.errorf(path, "expected number, got %v", kind(v))
+			return
+		}
+		s.bounds(path, c, n)
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			s.errorf(path, "expected boolean, got %v", kind(v))
+		}
+
+	case "object":
+		if _, ok := v.(map[string]interface{}); !ok {
+			s.errorf(path, "expected object, got %v", kind(v))
+		}
+	}
+
+	if len(c.Enum) > 0 && !containsString(c.Enum, fmt.Sprint(v)) {
+		s.errorf(path, "expected one of %v, got %v", strings.Join(c.Enum, ", "), v)
+	}
+}
+
+func (s *validator) bounds(path string, c constraints, n json.Number) {
+	f, err := n.Float64()
+	if err != nil {
+		return
+	}
+	if c.Minimum != nil && f < *c.Minimum {
+		s.errorf(path, "expected at least %v, got %v", *c.Minimum, n)
+	}
+	if c.Maximum != nil && f > *c.Maximum {
+		s.errorf(path, "expected at most %v, got %v", *c.Maximum, n)
+	}
+}
+
+func (s *validator) length(path string, c constraints, n int) {
+	if c.MinLength != nil && n < *c.MinLength {
+		s.errorf(path, "expected at least %v characters, got %v", *c.MinLength, n)
+	}
+	if c.MaxLength != nil && n > *c.MaxLength {
+		s.errorf(path, "expected at most %v characters, got %v", *c.MaxLength, n)
+	}
+}
+
+func validateFormat(format, value string) error {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("expected %v, got %q", format, value)
+	}
+	return nil
+}
+
+// kind describes the json type of v for error messages
+func kind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,11 @@
 package swagger
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
 
 // Items represents items from the swagger doc
 type Items struct {
@@ -14,8 +19,12 @@ type Schema struct {
 	Type      string      `json:"type,omitempty"`
 	Items     *Items      `json:"items,omitempty"`
 	Ref       string      `json:"$ref,omitempty"`
+	Example   interface{} `json:"example,omitempty"`
 	Prototype interface{} `json:"-"`
 	TypeAlias string      `json:"-"`
+
+	// Payload is the original schema of a response wrapped by API.Envelope
+	Payload *Schema `json:"-"`
 }
 
 // Header represents a response header
@@ -27,20 +36,42 @@ type Header struct {
 
 // Response represents a response from the swagger doc
 type Response struct {
-	Description string            `json:"description,omitempty"`
-	Schema      *Schema           `json:"schema,omitempty"`
-	Headers     map[string]Header `json:"headers,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Schema      *Schema                `json:"schema,omitempty"`
+	Headers     map[string]Header      `json:"headers,omitempty"`
+	Examples    map[string]interface{} `json:"examples,omitempty"`
+
+	// MediaTypes lists the content types of this response; swagger 2.0 only has an endpoint wide produces list, so
+	// they are merged into the endpoint's Produces by API.AddEndpoint
+	MediaTypes []string `json:"-"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // Parameter represents a parameter from the swagger doc
 type Parameter struct {
-	In          string  `json:"in,omitempty"`
-	Name        string  `json:"name,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Required    bool    `json:"required"`
-	Schema      *Schema `json:"schema,omitempty"`
-	Type        string  `json:"type,omitempty"`
-	Format      string  `json:"format,omitempty"`
+	In          string   `json:"in,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required"`
+	Schema      *Schema  `json:"schema,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+
+	// Minimum and Maximum bound numeric values; MinLength and MaxLength bound the length of strings
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+
+	// swagger 2.0 has no examples on parameters; request body examples by media type are emitted as x-examples
+	Examples map[string]interface{} `json:"x-examples,omitempty"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // Endpoint represents an endpoint from the swagger doc
@@ -56,9 +87,23 @@ type Endpoint struct {
 	Handler     interface{}         `json:"-"`
 	Parameters  []Parameter         `json:"parameters,omitempty"`
 	Responses   map[string]Response `json:"responses,omitempty"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+
+	// NoEnvelope excludes the endpoint's responses from API.Envelope
+	NoEnvelope bool `json:"-"`
+
+	// DeprecatedSince is when the endpoint was deprecated, Sunset is when a deprecated endpoint will stop responding
+	// and Successor is the url of its replacement.  They are advertised by the Deprecation, Sunset and Link response
+	// headers, see SetDeprecationHeaders
+	DeprecatedSince time.Time `json:"-"`
+	Sunset          time.Time `json:"-"`
+	Successor       string    `json:"-"`
 
 	// swagger spec requires security to be an array of objects
 	Security *SecurityRequirement `json:"security,omitempty"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // SecurityRequirement security requirement
@@ -75,3 +120,35 @@ func (s *SecurityRequirement) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(s.Requirements)
 }
+
+// UnmarshalJSON security requirement json; an empty array explicitly disables security
+func (s *SecurityRequirement) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Requirements); err != nil {
+		return err
+	}
+
+	s.DisableSecurity = s.Requirements != nil && len(s.Requirements) == 0
+	return nil
+}
+
+// SetDeprecationHeaders sets the Deprecation, Sunset and Link: rel="successor-version" headers for a deprecated
+// endpoint; does nothing if the endpoint isn't deprecated.  Deprecation is the RFC 9745 date, e.g. @1688169599, when
+// DeprecatedSince is known and the earlier draft's true otherwise.  Endpoints.ServeHTTP calls this automatically, routers that
+// bind endpoint handlers directly, e.g. via API.Walk, may call it themselves
+func SetDeprecationHeaders(h http.Header, e *Endpoint) {
+	if !e.Deprecated {
+		return
+	}
+
+	if e.DeprecatedSince.IsZero() {
+		h.Set("Deprecation", "true")
+	} else {
+		h.Set("Deprecation", "@"+strconv.FormatInt(e.DeprecatedSince.Unix(), 10))
+	}
+	if !e.Sunset.IsZero() {
+		h.Set("Sunset", e.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if e.Successor != "" {
+		h.Add("Link", "<"+e.Successor+`>; rel="successor-version"`)
+	}
+}
@@ -1,11 +1,13 @@
 package endpoint
 
 import (
+	"fmt"
 	"github.com/threeq/docs/swagger"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Builder uses the builder pattern to generate swagger endpoint definitions
@@ -117,6 +119,34 @@ func Body(prototype interface{}, description string, required bool) Option {
 	return BodyType(reflect.TypeOf(prototype), description, required)
 }
 
+// BodyExample attaches an example request body for the specified media type to the endpoint's body parameter; must
+// follow the Body or BodyType option.  The example for a json media type is also used as the body schema's example
+func BodyExample(mediaType string, v interface{}) Option {
+	return func(b *Builder) {
+		for i, p := range b.Endpoint.Parameters {
+			if p.In != "body" {
+				continue
+			}
+
+			if p.Examples == nil {
+				p.Examples = map[string]interface{}{}
+			}
+			p.Examples[mediaType] = v
+
+			if p.Schema != nil && strings.Contains(mediaType, "json") {
+				schema := *p.Schema
+				schema.Example = v
+				p.Schema = &schema
+			}
+
+			b.Endpoint.Parameters[i] = p
+			return
+		}
+
+		panic(fmt.Errorf("BodyExample requires a body parameter; use Body or BodyType first"))
+	}
+}
+
 // Tags allows one or more tags to be associated with the endpoint
 func Tags(tags ...string) Option {
 	return func(b *Builder) {
@@ -150,6 +180,57 @@ func NoSecurity() Option {
 	}
 }
 
+// Paginated declares a list endpoint paged in the specified style: the style's query parameters, a 200 response holding
+// an array of itemPrototype, wrapped by the api's envelope if any, and the X-Total-Count and Link response headers.
+// Handlers read the parameters with swagger.ParsePagination
+func Paginated(style swagger.PaginationStyle, itemPrototype interface{}, opts ...ResponseOption) Option {
+	var params []Option
+	switch style {
+	case swagger.OffsetPagination:
+		params = append(params,
+			Query(swagger.OffsetParam, "integer", "number of items to skip", false),
+			Query(swagger.LimitParam, "integer", "maximum number of items to return", false),
+		)
+	case swagger.PagePagination:
+		params = append(params,
+			Query(swagger.PageParam, "integer", "page number, starting at 1", false),
+			Query(swagger.PageSizeParam, "integer", "number of items per page", false),
+		)
+	case swagger.CursorPagination:
+		params = append(params,
+			Query(swagger.CursorParam, "string", "cursor returned by the previous page", false),
+			Query(swagger.LimitParam, "integer", "maximum number of items to return", false),
+		)
+	default:
+		panic(fmt.Errorf("Paginated: unknown pagination style, %v", style))
+	}
+
+	t := reflect.TypeOf(itemPrototype)
+	if v, ok := itemPrototype.(reflect.Type); ok {
+		t = v
+	}
+
+	headers := []ResponseOption{Header(swagger.LinkHeader, "string", "", "links to the first, previous, next and last pages")}
+	if style != swagger.CursorPagination {
+		headers = append(headers, Header(swagger.TotalCountHeader, "integer", "int64", "total number of items"))
+	}
+	response := ResponseType(http.StatusOK, reflect.SliceOf(t), "", "a page of results", append(headers, opts...)...)
+
+	return func(b *Builder) {
+		for _, opt := range params {
+			opt(b)
+		}
+		response(b)
+	}
+}
+
+// NoEnvelope excludes the endpoint's responses from the api's envelope, e.g. for downloads or health checks
+func NoEnvelope() Option {
+	return func(b *Builder) {
+		b.Endpoint.NoEnvelope = true
+	}
+}
+
 // ResponseOption allows for additional configurations on responses like header information
 type ResponseOption func(response *swagger.Response)
 
@@ -173,6 +254,56 @@ func Header(name, typ, format, description string) ResponseOption {
 	}
 }
 
+// Example attaches an example response body for the specified media type e.g. application/json
+func Example(mediaType string, v interface{}) ResponseOption {
+	return func(response *swagger.Response) {
+		if response.Examples == nil {
+			response.Examples = map[string]interface{}{}
+		}
+
+		response.Examples[mediaType] = v
+	}
+}
+
+// MediaTypes sets the content types of the response e.g. text/csv; they are added to the endpoint's produces when the
+// endpoint is added to an api
+func MediaTypes(types ...string) ResponseOption {
+	return func(response *swagger.Response) {
+		response.MediaTypes = types
+	}
+}
+
+// File declares a binary download response, type file, for the specified code; mediaTypes defaults to
+// application/octet-stream
+func File(code int, description string, mediaTypes ...string) Option {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/octet-stream"}
+	}
+
+	return func(b *Builder) {
+		if b.Endpoint.Responses == nil {
+			b.Endpoint.Responses = map[string]swagger.Response{}
+		}
+
+		b.Endpoint.Responses[strconv.Itoa(code)] = swagger.Response{
+			Description: description,
+			Schema:      &swagger.Schema{Type: "file"},
+			MediaTypes:  mediaTypes,
+		}
+	}
+}
+
+// ResponseExtension sets a vendor extension, x-*, on the response
+func ResponseExtension(name string, value interface{}) ResponseOption {
+	swagger.ValidateExtension(name)
+	return func(response *swagger.Response) {
+		if response.Extensions == nil {
+			response.Extensions = map[string]interface{}{}
+		}
+		response.Extensions[name] = value
+	}
+}
+
 // ResponseType sets the endpoint response for the specified code; may be used multiple times with different status codes
 // t represents the Type of the response
 func ResponseType(code int, t reflect.Type, name string, description string, opts ...ResponseOption) Option {
@@ -194,7 +325,8 @@ func ResponseType(code int, t reflect.Type, name string, description string, opt
 	}
 }
 
-// Response sets the endpoint response for the specified code; may be used multiple times with different status codes
+// Response sets the endpoint response for the specified code; may be used multiple times with different status codes.
+// A nil prototype declares a response without a body
 func Response(code int, prototype interface{}, name string, description string, opts ...ResponseOption) Option {
 	return func(b *Builder) {
 		if b.Endpoint.Responses == nil {
@@ -203,7 +335,11 @@ func Response(code int, prototype interface{}, name string, description string,
 
 		r := swagger.Response{
 			Description: description,
-			Schema:      swagger.MakeSchema(name, prototype),
+		}
+
+		// a nil prototype declares a response without a body e.g. 204 No Content
+		if prototype != nil {
+			r.Schema = swagger.MakeSchema(name, prototype)
 		}
 
 		for _, opt := range opts {
@@ -214,6 +350,167 @@ func Response(code int, prototype interface{}, name string, description string,
 	}
 }
 
+// Extension sets a vendor extension, x-*, on the endpoint e.g. Extension("x-rate-limit", 100)
+func Extension(name string, value interface{}) Option {
+	swagger.ValidateExtension(name)
+	return func(b *Builder) {
+		if b.Endpoint.Extensions == nil {
+			b.Endpoint.Extensions = map[string]interface{}{}
+		}
+		b.Endpoint.Extensions[name] = value
+	}
+}
+
+// ParameterExtension sets a vendor extension, x-*, on the named parameter; must follow the option that declares the
+// parameter
+func ParameterExtension(parameter, name string, value interface{}) Option {
+	swagger.ValidateExtension(name)
+	return withParameter("ParameterExtension", parameter, func(p *swagger.Parameter) {
+		if p.Extensions == nil {
+			p.Extensions = map[string]interface{}{}
+		}
+		p.Extensions[name] = value
+	})
+}
+
+// Internal hides the endpoint from public views of the api; see swagger.API.Filter
+func Internal() Option {
+	return Extension(swagger.InternalExtension, true)
+}
+
+// Audiences restricts the endpoint to views of the api for the specified audiences e.g. Audiences("partners"); see
+// swagger.ForAudience
+func Audiences(audiences ...string) Option {
+	return Extension(swagger.AudiencesExtension, audiences)
+}
+
+// InternalParameter hides the named parameter from public views of the api; must follow the option that declares the
+// parameter
+func InternalParameter(name string) Option {
+	return ParameterExtension(name, swagger.InternalExtension, true)
+}
+
+// Since declares the first version the endpoint is available in; see versioning.Router
+func Since(version string) Option {
+	return Extension(swagger.SinceExtension, version)
+}
+
+// Until declares the last version the endpoint is available in; see versioning.Router
+func Until(version string) Option {
+	return Extension(swagger.UntilExtension, version)
+}
+
+// Deprecated marks the endpoint as deprecated.  sunset, if not zero, is when the endpoint will stop responding and
+// replacement, if not empty, is the url of its successor; both are advertised via response headers when the endpoint is
+// served by swagger.Endpoints and recorded in the x-sunset and x-successor extensions
+func Deprecated(sunset time.Time, replacement string) Option {
+	return func(b *Builder) {
+		b.Endpoint.Deprecated = true
+		b.Endpoint.Sunset = sunset
+		b.Endpoint.Successor = replacement
+
+		if b.Endpoint.Extensions == nil {
+			b.Endpoint.Extensions = map[string]interface{}{}
+		}
+		if !sunset.IsZero() {
+			b.Endpoint.Extensions["x-sunset"] = sunset.UTC().Format(time.RFC3339)
+		}
+		if replacement != "" {
+			b.Endpoint.Extensions["x-successor"] = replacement
+		}
+	}
+}
+
+// DeprecatedSince marks the endpoint as deprecated since t; the date is advertised by the Deprecation response header
+// and recorded in the x-deprecated-since extension
+func DeprecatedSince(t time.Time) Option {
+	return func(b *Builder) {
+		b.Endpoint.Deprecated = true
+		b.Endpoint.DeprecatedSince = t
+
+		if b.Endpoint.Extensions == nil {
+			b.Endpoint.Extensions = map[string]interface{}{}
+		}
+		b.Endpoint.Extensions["x-deprecated-since"] = t.UTC().Format(time.RFC3339)
+	}
+}
+
+// DeprecatedParameter marks the named parameter as deprecated; must follow the option that declares the parameter
+func DeprecatedParameter(name string) Option {
+	return withParameter("DeprecatedParameter", name, func(p *swagger.Parameter) {
+		p.Deprecated = true
+	})
+}
+
+// Enum restricts the named parameter to the values; must follow the option that declares the parameter
+func Enum(name string, values ...string) Option {
+	return withParameter("Enum", name, func(p *swagger.Parameter) {
+		p.Enum = values
+	})
+}
+
+// Minimum sets the minimum value of the named numeric parameter; must follow the option that declares the parameter
+func Minimum(name string, v float64) Option {
+	return withParameter("Minimum", name, func(p *swagger.Parameter) {
+		p.Minimum = &v
+	})
+}
+
+// Maximum sets the maximum value of the named numeric parameter; must follow the option that declares the parameter
+func Maximum(name string, v float64) Option {
+	return withParameter("Maximum", name, func(p *swagger.Parameter) {
+		p.Maximum = &v
+	})
+}
+
+// MinLength sets the minimum length of the named string parameter; must follow the option that declares the parameter
+func MinLength(name string, n int) Option {
+	return withParameter("MinLength", name, func(p *swagger.Parameter) {
+		p.MinLength = &n
+	})
+}
+
+// MaxLength sets the maximum length of the named string parameter; must follow the option that declares the parameter
+func MaxLength(name string, n int) Option {
+	return withParameter("MaxLength", name, func(p *swagger.Parameter) {
+		p.MaxLength = &n
+	})
+}
+
+// withParameter returns an option that applies fn to the named parameter; panics, naming the option, if the parameter
+// hasn't been declared
+func withParameter(option, name string, fn func(p *swagger.Parameter)) Option {
+	return func(b *Builder) {
+		for i := range b.Endpoint.Parameters {
+			if b.Endpoint.Parameters[i].Name == name {
+				fn(&b.Endpoint.Parameters[i])
+				return
+			}
+		}
+
+		panic(fmt.Errorf("%v requires parameter %v to be declared first", option, name))
+	}
+}
+
+// Errors declares RFC 7807 problem responses for each of the specified status codes
+func Errors(codes ...int) Option {
+	return func(b *Builder) {
+		if b.Endpoint.Responses == nil {
+			b.Endpoint.Responses = map[string]swagger.Response{}
+		}
+
+		for _, code := range codes {
+			b.Endpoint.Responses[strconv.Itoa(code)] = swagger.ProblemResponse(http.StatusText(code))
+		}
+	}
+}
+
+// StandardErrors declares RFC 7807 problem responses for the common error statuses; 400, 401, 403, 404, 409, 422,
+// and 500
+func StandardErrors() Option {
+	return Errors(swagger.StandardErrorCodes...)
+}
+
 // New constructs a new swagger endpoint using the fields and functional options provided
 func New(method, path, summary string, options ...Option) *swagger.Endpoint {
 	method = strings.ToUpper(method)
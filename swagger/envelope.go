@@ -0,0 +1,178 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Envelope describes a wrapper, e.g. {code, message, data}, that every successful response body is placed in.  The
+// payload is held by the wrapper field whose json name is Field
+type Envelope struct {
+	Prototype interface{}
+	Field     string
+
+	t     reflect.Type
+	index int
+}
+
+// NewEnvelope returns an Envelope for the struct prototype whose interface{} field, named field in json, holds the
+// payload; panics if there is no such field
+func NewEnvelope(prototype interface{}, field string) *Envelope {
+	t := reflect.TypeOf(prototype)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("NewEnvelope requires a struct prototype; got %v", t))
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		if name != field {
+			continue
+		}
+
+		if f.Type.Kind() != reflect.Interface || f.Type.NumMethod() != 0 {
+			panic(fmt.Errorf("NewEnvelope requires %v.%v to be an interface{}", t, f.Name))
+		}
+		return &Envelope{
+			Prototype: prototype,
+			Field:     field,
+			t:         t,
+			index:     i,
+		}
+	}
+
+	panic(fmt.Errorf("NewEnvelope: %v has no field named %v", t, field))
+}
+
+// Wrap returns a copy of the prototype with v as its payload; v is returned as is when the prototype is a nil pointer
+func (e *Envelope) Wrap(v interface{}) interface{} {
+	prototype := reflect.Indirect(reflect.ValueOf(e.Prototype))
+	if !prototype.IsValid() {
+		return v
+	}
+
+	wrapper := reflect.New(e.t).Elem()
+	wrapper.Set(prototype)
+	if v != nil {
+		wrapper.Field(e.index).Set(reflect.ValueOf(v))
+	}
+	return wrapper.Interface()
+}
+
+// Write writes v, wrapped in the envelope, to w as json with the specified status
+func (e *Envelope) Write(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(e.Wrap(v))
+}
+
+// envelopeName returns the name of the composed definition for the payload schema e.g. modelsUserResponse
+func envelopeName(payload *Schema) string {
+	name := defineObject(payload.TypeAlias, payload.Prototype).Name
+	if payload.Type == "array" {
+		return name + "ListResponse"
+	}
+	return name + "Response"
+}
+
+// define returns the definitions of the payload along with the envelope composed with it
+func (e *Envelope) define(schema *Schema) map[string]Object {
+	payload := schema.Payload
+	objMap := define(payload.TypeAlias, payload.Prototype)
+
+	name := strings.TrimPrefix(schema.Ref, "#/definitions/")
+	for k, v := range define(name, e.Prototype) {
+		if _, ok := objMap[k]; !ok {
+			objMap[k] = v
+		}
+	}
+
+	obj := objMap[name]
+	properties := make(map[string]Property, len(obj.Properties))
+	for k, v := range obj.Properties {
+		properties[k] = v
+	}
+
+	p := Property{Description: properties[e.Field].Description}
+	if payload.Type == "array" {
+		p.Type = "array"
+		p.Items = payload.Items
+	} else {
+		p.Ref = payload.Ref
+	}
+	properties[e.Field] = p
+
+	obj.Properties = properties
+	obj.Description = ""
+	objMap[name] = obj
+
+	return objMap
+}
+
+// addEnvelope replaces the schemas of successful responses with schemas that reference the envelope composed with the
+// original payload, and wraps their examples to match; examples for media types other than json can't be wrapped and
+// are dropped.  Endpoints with NoEnvelope, responses that were already wrapped and responses that aren't json, such
+// as a text/csv download, are left alone
+func (a *API) addEnvelope(e *Endpoint) {
+	if a.Envelope == nil || e.NoEnvelope {
+		return
+	}
+
+	for key, response := range e.Responses {
+		code, err := strconv.Atoi(key)
+		if err != nil || code < 200 || code >= 300 {
+			continue
+		}
+		if response.Schema == nil || response.Schema.Prototype == nil || response.Schema.Payload != nil {
+			continue
+		}
+		if !isJSON(response.MediaTypes, e.Produces) {
+			continue
+		}
+
+		response.Schema = &Schema{
+			Ref:     makeRef(envelopeName(response.Schema)),
+			Payload: response.Schema,
+		}
+		if example := response.Schema.Payload.Example; example != nil {
+			response.Schema.Example = a.Envelope.Wrap(example)
+		}
+		if response.Examples != nil {
+			examples := map[string]interface{}{}
+			for mediaType, example := range response.Examples {
+				if strings.Contains(mediaType, "json") {
+					examples[mediaType] = a.Envelope.Wrap(example)
+				}
+			}
+			response.Examples = examples
+		}
+		e.Responses[key] = response
+	}
+}
+
+// isJSON reports whether a response with the media types, or the endpoint's produces when it has none, can be json;
+// responses without either are assumed to be json
+func isJSON(mediaTypes, produces []string) bool {
+	if len(mediaTypes) == 0 {
+		mediaTypes = produces
+	}
+	if len(mediaTypes) == 0 {
+		return true
+	}
+	for _, mediaType := range mediaTypes {
+		if strings.Contains(mediaType, "json") {
+			return true
+		}
+	}
+	return false
+}
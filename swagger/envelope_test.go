@@ -0,0 +1,128 @@
+package swagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelopeDefinitions(t *testing.T) {
+	api := &API{Envelope: NewEnvelope(APIResponse{}, "data")}
+	api.AddEndpoint(&Endpoint{
+		Method: "GET",
+		Path:   "/people/{id}",
+		Responses: map[string]Response{
+			"200": {Schema: MakeSchema("", Person{})},
+			"404": ProblemResponse("not found"),
+		},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method: "GET",
+		Path:   "/people",
+		Responses: map[string]Response{
+			"200": {Schema: MakeSchema("", []Person{})},
+		},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method:     "GET",
+		Path:       "/health",
+		NoEnvelope: true,
+		Responses: map[string]Response{
+			"200": {Schema: MakeSchema("", Person{})},
+		},
+	})
+
+	get := api.Paths["/people/{id}"].Get
+	assert.Equal(t, "#/definitions/swaggerPersonResponse", get.Responses["200"].Schema.Ref)
+	assert.Equal(t, "#/definitions/swaggerProblemDetails", get.Responses["404"].Schema.Ref)
+
+	list := api.Paths["/people"].Get
+	assert.Equal(t, "#/definitions/swaggerPersonListResponse", list.Responses["200"].Schema.Ref)
+
+	health := api.Paths["/health"].Get
+	assert.Equal(t, "#/definitions/swaggerPerson", health.Responses["200"].Schema.Ref)
+
+	single := api.Definitions["swaggerPersonResponse"]
+	assert.Len(t, single.Properties, 3)
+	assert.Equal(t, "integer", single.Properties["code"].Type)
+	assert.Equal(t, "#/definitions/swaggerPerson", single.Properties["data"].Ref)
+
+	many := api.Definitions["swaggerPersonListResponse"]
+	assert.Equal(t, "array", many.Properties["data"].Type)
+	assert.Equal(t, &Items{Ref: "#/definitions/swaggerPerson"}, many.Properties["data"].Items)
+
+	assert.Contains(t, api.Definitions, "swaggerPerson")
+	assert.NotContains(t, api.Definitions, "swaggerAPIResponse")
+
+	// adding the endpoint again doesn't wrap twice
+	api.AddEndpoint(get)
+	assert.Equal(t, "#/definitions/swaggerPersonResponse", get.Responses["200"].Schema.Ref)
+}
+
+func TestEnvelopeExamples(t *testing.T) {
+	joe := Person{First: "Joe"}
+	schema := MakeSchema("", Person{})
+	schema.Example = joe
+
+	api := &API{Envelope: NewEnvelope(APIResponse{}, "data")}
+	api.AddEndpoint(&Endpoint{
+		Method: "GET",
+		Path:   "/people/{id}",
+		Responses: map[string]Response{
+			"200": {
+				Schema:   schema,
+				Examples: map[string]interface{}{"application/json": joe, "application/xml": "<person/>"},
+			},
+		},
+	})
+
+	response := api.Paths["/people/{id}"].Get.Responses["200"]
+	assert.Equal(t, APIResponse{Data: joe}, response.Schema.Example)
+	assert.Equal(t, map[string]interface{}{"application/json": APIResponse{Data: joe}}, response.Examples)
+	assert.Equal(t, joe, response.Schema.Payload.Example)
+}
+
+func TestEnvelopeSkipsOtherMediaTypes(t *testing.T) {
+	api := &API{Envelope: NewEnvelope(APIResponse{}, "data")}
+	api.AddEndpoint(&Endpoint{
+		Method: "GET",
+		Path:   "/people.csv",
+		Responses: map[string]Response{
+			"200": {Schema: MakeSchema("", ""), MediaTypes: []string{"text/csv"}},
+		},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method: "GET",
+		Path:   "/people",
+		Responses: map[string]Response{
+			"200": {Schema: MakeSchema("", Person{}), MediaTypes: []string{"text/csv", "application/json"}},
+		},
+	})
+
+	assert.Equal(t, "#/definitions/string", api.Paths["/people.csv"].Get.Responses["200"].Schema.Ref)
+	assert.NotContains(t, api.Definitions, "stringResponse")
+	assert.Equal(t, "#/definitions/swaggerPersonResponse", api.Paths["/people"].Get.Responses["200"].Schema.Ref)
+}
+
+func TestEnvelopeWrite(t *testing.T) {
+	envelope := NewEnvelope(&APIResponse{Message: "ok"}, "data")
+
+	v := envelope.Wrap(Person{First: "Joe"})
+	assert.Equal(t, APIResponse{Message: "ok", Data: Person{First: "Joe"}}, v)
+
+	w := httptest.NewRecorder()
+	assert.Nil(t, envelope.Write(w, http.StatusOK, nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"code":0,"message":"ok","data":null}`, w.Body.String())
+
+	// a nil prototype has nothing to wrap the payload in
+	assert.Equal(t, Person{First: "Joe"}, NewEnvelope((*APIResponse)(nil), "data").Wrap(Person{First: "Joe"}))
+}
+
+func TestNewEnvelopePanics(t *testing.T) {
+	assert.Panics(t, func() { NewEnvelope("string", "data") })
+	assert.Panics(t, func() { NewEnvelope(APIResponse{}, "payload") })
+	assert.Panics(t, func() { NewEnvelope(APIResponse{}, "message") })
+}
@@ -0,0 +1,234 @@
+package swagger
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxExampleDepth limits how deeply self-referencing definitions are expanded when synthesizing examples
+const maxExampleDepth = 5
+
+// exampleEpoch anchors the dates of seeded examples so they remain deterministic
+var exampleEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ExampleOption customizes the examples synthesized by Example and SchemaExample
+type ExampleOption func(s *synthesizer)
+
+// ExampleSeed varies the synthesized values, e.g. for a mock server, while following the same rules as the examples
+// shown in the docs: enums pick one of their values, polymorphic definitions one of their implementations, arrays hold
+// one to three items and primitives take random values in the shape of their format.  The same seed always
+// synthesizes the same example
+func ExampleSeed(seed int64) ExampleOption {
+	return func(s *synthesizer) {
+		s.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// Example synthesizes a plausible json instance of obj; definitions are used to resolve $ref.  Explicit example values
+// and enums take precedence over values generated from the type and format
+func Example(obj Object, definitions map[string]Object, options ...ExampleOption) interface{} {
+	s := newSynthesizer(definitions, options)
+	v := s.object(obj)
+	if obj.IsArray {
+		return []interface{}{v}
+	}
+	return v
+}
+
+// SchemaExample synthesizes a plausible json instance of the schema; an explicit example on the schema is returned as is
+func SchemaExample(schema *Schema, definitions map[string]Object, options ...ExampleOption) interface{} {
+	if schema == nil {
+		return nil
+	}
+
+	s := newSynthesizer(definitions, options)
+	return s.schema(schema)
+}
+
+// synthesizer generates examples; without a rand, the canonical example of each type is chosen
+type synthesizer struct {
+	definitions map[string]Object
+	depth       int
+	rand        *rand.Rand
+}
+
+func newSynthesizer(definitions map[string]Object, options []ExampleOption) *synthesizer {
+	s := &synthesizer{definitions: definitions}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// intn returns a random number in [0, n), or 0 for canonical examples
+func (s *synthesizer) intn(n int) int {
+	if s.rand == nil {
+		return 0
+	}
+	return s.rand.Intn(n)
+}
+
+func (s *synthesizer) schema(schema *Schema) interface{} {
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Ref != "":
+		return s.ref(schema.Ref)
+	case schema.Type == "array" && schema.Items != nil:
+		return s.array(schema.Items, "")
+	default:
+		return s.primitive(schema.Type, "", "")
+	}
+}
+
+func (s *synthesizer) ref(ref string) interface{} {
+	obj, ok := s.definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if !ok || s.depth >= maxExampleDepth {
+		return nil
+	}
+
+	s.depth++
+	defer func() { s.depth-- }()
+
+	return s.object(obj)
+}
+
+func (s *synthesizer) object(obj Object) interface{} {
+	// a polymorphic definition is exemplified by one of its implementations, the first for canonical examples
+	if obj.Discriminator != "" {
+		if names := Implementations(s.definitions, obj.Name); len(names) > 0 {
+			obj = s.definitions[names[s.intn(len(names))]]
+		}
+	}
+	obj = Flatten(obj, s.definitions)
+
+	if obj.Type != "object" {
+		return s.primitive(obj.Type, obj.Format, "")
+	}
+
+	// visit properties in a stable order so the same seed always synthesizes the same values
+	names := make([]string, 0, len(obj.Properties))
+	for name := range obj.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	v := map[string]interface{}{}
+	for _, name := range names {
+		v[name] = s.property(name, obj.Properties[name])
+	}
+	return v
+}
+
+func (s *synthesizer) property(name string, p Property) interface{} {
+	switch {
+	case p.Example != nil:
+		return p.Example
+	case len(p.Enum) > 0:
+		return p.Enum[s.intn(len(p.Enum))]
+	case p.Ref != "":
+		return s.ref(p.Ref)
+	case p.Type == "array" && p.Items != nil:
+		return s.array(p.Items, name)
+	case p.AdditionalProperties != nil:
+		v := map[string]interface{}{}
+		for i, item := range s.array(p.AdditionalProperties, name) {
+			key := "key"
+			if i > 0 {
+				key = fmt.Sprintf("key%v", i+1)
+			}
+			v[key] = item
+		}
+		return v
+	default:
+		return s.primitive(p.Type, p.Format, name)
+	}
+}
+
+// array returns the items of an array; one for canonical examples, else one to three
+func (s *synthesizer) array(items *Items, name string) []interface{} {
+	n := 1 + s.intn(3)
+	values := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		if items.Ref != "" {
+			values = append(values, s.ref(items.Ref))
+		} else {
+			values = append(values, s.primitive(items.Type, items.Format, name))
+		}
+	}
+	return values
+}
+
+// primitive returns a value of the type and format; name, the property the value is for, distinguishes seeded strings
+func (s *synthesizer) primitive(typ, format, name string) interface{} {
+	if s.rand == nil {
+		return primitiveExample(typ, format)
+	}
+
+	switch typ {
+	case "integer":
+		return s.rand.Intn(1000)
+	case "number":
+		return float64(s.rand.Intn(100000)) / 100
+	case "boolean":
+		return s.rand.Intn(2) == 1
+	case "string":
+		n := s.rand.Intn(1000)
+		switch format {
+		case "date-time":
+			return exampleEpoch.Add(time.Duration(s.rand.Intn(365*24)) * time.Hour).Format(time.RFC3339)
+		case "date":
+			return exampleEpoch.AddDate(0, 0, s.rand.Intn(365)).Format("2006-01-02")
+		case "email":
+			return fmt.Sprintf("user%v@example.com", n)
+		case "uuid":
+			return fmt.Sprintf("00000000-0000-4000-8000-%012x", s.rand.Int63n(1<<48))
+		case "uri":
+			return fmt.Sprintf("https://example.com/%v", n)
+		case "byte":
+			return primitiveExample(typ, format)
+		}
+		if name == "" {
+			name = "string"
+		}
+		return fmt.Sprintf("%v-%v", name, n)
+	case "file":
+		return fmt.Sprintf("file-%v", s.rand.Intn(1000))
+	}
+	return primitiveExample(typ, format)
+}
+
+func primitiveExample(typ, format string) interface{} {
+	switch typ {
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	case "string":
+		switch format {
+		case "date-time":
+			return "2020-01-01T00:00:00Z"
+		case "date":
+			return "2020-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri":
+			return "https://example.com"
+		case "byte":
+			return "c3RyaW5n"
+		}
+		return "string"
+	case "object":
+		return map[string]interface{}{}
+	case "file":
+		return "file"
+	}
+	return nil
+}
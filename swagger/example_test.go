@@ -0,0 +1,92 @@
+package swagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Tagged struct {
+	ID      int64    `json:"id" example:"42"`
+	Price   float64  `json:"price" example:"9.99"`
+	Active  bool     `json:"active" example:"true"`
+	Name    string   `json:"name" example:"fido"`
+	Tags    []string `json:"tags" example:"a, b"`
+	Scores  []int    `json:"scores" example:"[1,2]"`
+	Owner   *Person  `json:"owner"`
+	Created string   `json:"created"`
+}
+
+func TestExampleTag(t *testing.T) {
+	obj := define("", Tagged{})["swaggerTagged"]
+	assert.Equal(t, int64(42), obj.Properties["id"].Example)
+	assert.Equal(t, 9.99, obj.Properties["price"].Example)
+	assert.Equal(t, true, obj.Properties["active"].Example)
+	assert.Equal(t, "fido", obj.Properties["name"].Example)
+	assert.Equal(t, []interface{}{"a", "b"}, obj.Properties["tags"].Example)
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, obj.Properties["scores"].Example)
+	assert.Nil(t, obj.Properties["created"].Example)
+
+	type Invalid struct {
+		Count int `json:"count" example:"many"`
+	}
+	assert.PanicsWithError(t, `Invalid.Count: example tag "many" isn't a valid integer`, func() { define("", Invalid{}) })
+
+	type InvalidItems struct {
+		Scores []int `json:"scores" example:"1, lots"`
+	}
+	assert.PanicsWithError(t, `InvalidItems.Scores: example tag "lots" isn't a valid integer`, func() { define("", InvalidItems{}) })
+}
+
+func TestExample(t *testing.T) {
+	definitions := define("", Tagged{})
+	v := Example(definitions["swaggerTagged"], definitions).(map[string]interface{})
+
+	assert.Equal(t, int64(42), v["id"])
+	assert.Equal(t, "fido", v["name"])
+	assert.Equal(t, "string", v["created"])
+	assert.Equal(t, map[string]interface{}{"First": "string"}, v["owner"])
+}
+
+func TestSchemaExample(t *testing.T) {
+	definitions := define("", []Person{})
+
+	v := SchemaExample(MakeSchema("", []Person{}), definitions)
+	assert.Equal(t, []interface{}{map[string]interface{}{"First": "string"}}, v)
+
+	schema := MakeSchema("", Person{})
+	schema.Example = map[string]interface{}{"First": "joe"}
+	assert.Equal(t, schema.Example, SchemaExample(schema, definitions))
+
+	assert.Nil(t, SchemaExample(nil, definitions))
+}
+
+type Node struct {
+	Name  string `json:"name"`
+	Child *Node  `json:"child"`
+}
+
+func TestExampleRecursive(t *testing.T) {
+	definitions := define("", Node{})
+	v := Example(definitions["swaggerNode"], definitions)
+
+	depth := 0
+	for v != nil {
+		depth++
+		v = v.(map[string]interface{})["child"]
+	}
+	assert.Equal(t, maxExampleDepth+1, depth)
+}
+
+func TestExampleSeed(t *testing.T) {
+	definitions := define("", Tagged{})
+	obj := definitions["swaggerTagged"]
+
+	v := Example(obj, definitions, ExampleSeed(7)).(map[string]interface{})
+	assert.Equal(t, v, Example(obj, definitions, ExampleSeed(7)))
+	assert.Equal(t, int64(42), v["id"])
+	assert.IsType(t, "", v["created"])
+
+	items := SchemaExample(MakeSchema("", []Person{}), define("", []Person{}), ExampleSeed(7)).([]interface{})
+	assert.True(t, len(items) >= 1 && len(items) <= 3)
+}
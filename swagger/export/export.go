@@ -0,0 +1,288 @@
+// Package export converts a swagger.API into the collections of api clients such as Postman and Insomnia, and renders
+// curl, HTTPie and Go snippets per operation, so requests can be tried without writing them by hand
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Option provides configuration options to the exporters
+type Option func(c *config)
+
+type config struct {
+	baseURL string
+}
+
+// BaseURL sets the initial value of the baseUrl variable; defaults to the scheme, host and basePath of the api
+func BaseURL(url string) Option {
+	return func(c *config) {
+		c.baseURL = url
+	}
+}
+
+func newConfig(api *swagger.API, options []Option) *config {
+	c := &config{
+		baseURL: defaultBaseURL(api),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// baseURLVariable names the variable every request url starts with
+const baseURLVariable = "baseUrl"
+
+func defaultBaseURL(api *swagger.API) string {
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+
+	host := api.Host
+	if host == "" {
+		host = "localhost"
+	}
+
+	return scheme + "://" + host + strings.TrimSuffix(api.BasePath, "/")
+}
+
+// collectionName returns the name of the exported collection; the title of the api, else api
+func collectionName(api *swagger.API) string {
+	if api.Info.Title != "" {
+		return api.Info.Title
+	}
+	return "api"
+}
+
+// requestName returns the name a request is listed under; the summary, else the operationId, else the method and path
+func requestName(op swagger.Operation) string {
+	switch {
+	case op.Endpoint.Summary != "":
+		return op.Endpoint.Summary
+	case op.Endpoint.OperationID != "":
+		return op.Endpoint.OperationID
+	default:
+		return op.Method + " " + op.Path
+	}
+}
+
+type folder struct {
+	Name        string
+	Description string
+	Operations  []swagger.Operation
+}
+
+// folders groups the operations by their first tag; the api's tags come first in their declared order followed by
+// tags only named by endpoints.  Untagged operations are returned separately
+func folders(api *swagger.API, ops []swagger.Operation) ([]*folder, []swagger.Operation) {
+	var ordered []*folder
+	byName := map[string]*folder{}
+	add := func(name, description string) *folder {
+		if f, ok := byName[name]; ok {
+			return f
+		}
+		f := &folder{Name: name, Description: description}
+		byName[name] = f
+		ordered = append(ordered, f)
+		return f
+	}
+
+	for _, tag := range api.Tags {
+		add(tag.Name, tag.Description)
+	}
+
+	var untagged []swagger.Operation
+	for _, op := range ops {
+		if len(op.Endpoint.Tags) == 0 {
+			untagged = append(untagged, op)
+			continue
+		}
+		f := add(op.Endpoint.Tags[0], "")
+		f.Operations = append(f.Operations, op)
+	}
+
+	// tags without endpoints would be empty folders
+	var v []*folder
+	for _, f := range ordered {
+		if len(f.Operations) > 0 {
+			v = append(v, f)
+		}
+	}
+
+	return v, untagged
+}
+
+// pathVariables converts the swagger path template into the :name form used by the clients e.g. /pets/{id} =>
+// /pets/:id
+func pathVariables(rawPath string) string {
+	r := strings.NewReplacer("{", ":", "}", "")
+	return r.Replace(rawPath)
+}
+
+// parameterValue returns an example value for a non-body parameter as a string
+func parameterValue(p swagger.Parameter) string {
+	v := swagger.Example(swagger.Object{Type: p.Type, Format: p.Format}, nil)
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// contentType returns the media type of the request body; the first type the endpoint consumes, else json
+func contentType(e *swagger.Endpoint) string {
+	if len(e.Consumes) > 0 {
+		return e.Consumes[0]
+	}
+	return "application/json"
+}
+
+// bodyExample returns the example body of the endpoint, an explicit example for the content type taking precedence
+// over one synthesized from the schema, formatted as indented json; returns false if the endpoint has no body
+func bodyExample(api *swagger.API, e *swagger.Endpoint) (string, bool) {
+	for _, p := range e.Parameters {
+		if p.In != "body" {
+			continue
+		}
+
+		v, ok := p.Examples[contentType(e)]
+		if !ok {
+			v = swagger.SchemaExample(p.Schema, api.Definitions)
+		}
+		if s, ok := v.(string); ok && !strings.Contains(contentType(e), "json") {
+			return s, true
+		}
+
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", true
+		}
+		return string(data), true
+	}
+	return "", false
+}
+
+// authScheme is a security scheme requests authenticate with along with the scopes they require
+type authScheme struct {
+	Name   string
+	Scheme swagger.SecurityScheme
+	Scopes []string
+}
+
+// security returns the alternatives of the endpoint's effective security requirement, each listing its schemes sorted
+// by name; alternatives naming a scheme that isn't defined are left out.  Returns nil if requests to the endpoint
+// aren't authenticated
+func security(api *swagger.API, e *swagger.Endpoint) [][]authScheme {
+	requirement := api.EffectiveSecurity(e)
+	if requirement == nil {
+		return nil
+	}
+
+	var alternatives [][]authScheme
+	for _, r := range requirement.Requirements {
+		names := make([]string, 0, len(r))
+		for name := range r {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var schemes []authScheme
+		for _, name := range names {
+			scheme, ok := api.SecurityDefinitions[name]
+			if !ok {
+				schemes = nil
+				break
+			}
+			schemes = append(schemes, authScheme{Name: name, Scheme: scheme, Scopes: r[name]})
+		}
+		if len(schemes) > 0 {
+			alternatives = append(alternatives, schemes)
+		}
+	}
+	return alternatives
+}
+
+// singleAuth returns the scheme used by exports that attach a single auth to a request, Postman and Insomnia: the first
+// alternative made of one scheme or, failing that, the first scheme of the first alternative
+func singleAuth(api *swagger.API, e *swagger.Endpoint) (authScheme, bool) {
+	alternatives := security(api, e)
+	for _, schemes := range alternatives {
+		if len(schemes) == 1 {
+			return schemes[0], true
+		}
+	}
+	if len(alternatives) > 0 {
+		return alternatives[0][0], true
+	}
+	return authScheme{}, false
+}
+
+func schemeNames(api *swagger.API) []string {
+	names := make([]string, 0, len(api.SecurityDefinitions))
+	for name := range api.SecurityDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// credentials returns the names of the variables holding the credentials of the named scheme
+func credentials(name string, scheme swagger.SecurityScheme) []string {
+	switch scheme.Kind() {
+	case "basic":
+		return []string{name + "_username", name + "_password"}
+	case "apiKey", "bearer":
+		return []string{name}
+	case "oauth2":
+		return []string{name + "_client_id", name + "_client_secret"}
+	}
+	return nil
+}
+
+type oauthFlow struct {
+	// GrantType is the oauth 2 grant type e.g. authorization_code
+	GrantType        string
+	AuthorizationURL string
+	TokenURL         string
+}
+
+// flows maps the flows of swagger 2.0 and OpenAPI 3 to oauth 2 grant types
+var flows = []struct {
+	swagger2, openapi3, grantType string
+}{
+	{"accessCode", "authorizationCode", "authorization_code"},
+	{"application", "clientCredentials", "client_credentials"},
+	{"password", "password", "password"},
+	{"implicit", "implicit", "implicit"},
+}
+
+// flow returns the oauth 2 flow a client should use for the scheme, preferring the authorization code flow
+func flow(scheme swagger.SecurityScheme) oauthFlow {
+	for _, f := range flows {
+		if v, ok := scheme.Flows[f.openapi3]; ok {
+			return oauthFlow{GrantType: f.grantType, AuthorizationURL: v.AuthorizationURL, TokenURL: v.TokenURL}
+		}
+	}
+	for _, f := range flows {
+		if scheme.Flow == f.swagger2 {
+			return oauthFlow{GrantType: f.grantType, AuthorizationURL: scheme.AuthorizationURL, TokenURL: scheme.TokenURL}
+		}
+	}
+	return oauthFlow{GrantType: "authorization_code", AuthorizationURL: scheme.AuthorizationURL, TokenURL: scheme.TokenURL}
+}
+
+// marshal renders v as indented json followed by a newline
+func marshal(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,282 @@
+package export
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type Pet struct {
+	ID     int64    `json:"id" required:"true"`
+	Name   string   `json:"name" required:"true" example:"Rex"`
+	Tags   []string `json:"tags"`
+	Status string   `json:"status" enum:"available,pending,sold"`
+}
+
+func petstore() *swagger.API {
+	api := &swagger.API{
+		Swagger:  "2.0",
+		Host:     "api.example.com",
+		Schemes:  []string{"https"},
+		BasePath: "/v1",
+		Info:     swagger.Info{Title: "Petstore", Description: "the petstore"},
+		Tags:     []swagger.Tag{{Name: "pets", Description: "everything about pets"}, {Name: "stores"}},
+		SecurityDefinitions: map[string]swagger.SecurityScheme{
+			"api_key": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			"basic":   {Type: "basic"},
+			"oauth":   {Type: "oauth2", Flow: "accessCode", AuthorizationURL: "https://auth.example.com/authorize", TokenURL: "https://auth.example.com/token"},
+			"jwt":     {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			"session": {Type: "apiKey", Name: "session", In: "cookie"},
+		},
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{{"api_key": {}}}},
+	}
+
+	api.AddEndpoint(endpoint.Get("/pets", "list pets",
+		endpoint.OperationID("listPets"),
+		endpoint.Tags("pets"),
+		endpoint.Query("limit", "integer", "maximum number of pets", false),
+		endpoint.Query("status", "string", "status filter", true),
+		endpoint.Response(http.StatusOK, []Pet{}, "", "the pets"),
+	))
+	api.AddEndpoint(endpoint.Post("/pets", "create a pet",
+		endpoint.OperationID("createPet"),
+		endpoint.Tags("pets"),
+		endpoint.Body(Pet{}, "the pet", true),
+		endpoint.Response(http.StatusCreated, Pet{}, "", "created"),
+		endpoint.Security("oauth", "write"),
+	))
+	api.AddEndpoint(endpoint.Put("/pets/{id}", "replace a pet",
+		endpoint.OperationID("replacePet"),
+		endpoint.Tags("pets"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Body(Pet{}, "the pet", true),
+		endpoint.BodyExample("application/json", map[string]interface{}{"id": 1, "name": "Fido"}),
+		endpoint.Response(http.StatusOK, Pet{}, "", "the pet"),
+		endpoint.Security("jwt"),
+	))
+	api.AddEndpoint(endpoint.Delete("/pets/{id}", "",
+		endpoint.OperationID("deletePet"),
+		endpoint.Tags("pets"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusNoContent, nil, "", "deleted"),
+		endpoint.NoSecurity(),
+	))
+	api.AddEndpoint(&swagger.Endpoint{
+		Method:      "POST",
+		Path:        "/pets/{id}/photo",
+		Summary:     "upload a photo",
+		OperationID: "uploadPhoto",
+		Tags:        []string{"pets"},
+		Consumes:    []string{"multipart/form-data"},
+		Parameters: []swagger.Parameter{
+			{In: "path", Name: "id", Type: "integer", Required: true},
+			{In: "formData", Name: "caption", Type: "string"},
+			{In: "formData", Name: "photo", Type: "file", Required: true},
+		},
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{{"basic": {}}}},
+	})
+	api.AddEndpoint(endpoint.Get("/health", "health check",
+		endpoint.OperationID("health"),
+		endpoint.Security("session"),
+	))
+
+	return api
+}
+
+func golden(t *testing.T, name string, actual []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.Nil(t, ioutil.WriteFile(path, actual, 0644))
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestPostman(t *testing.T) {
+	data, err := Postman(petstore())
+	assert.Nil(t, err)
+	golden(t, "petstore.postman.json", data)
+
+	v := postmanCollection{}
+	assert.Nil(t, json.Unmarshal(data, &v))
+	assert.Equal(t, postmanSchema, v.Info.Schema)
+	assert.Equal(t, postmanVariable{Key: "baseUrl", Value: "https://api.example.com/v1", Type: "string"}, v.Variable[0])
+	assert.Equal(t, "apikey", v.Auth.Type)
+
+	// the untagged endpoint follows the folders
+	assert.Len(t, v.Item, 2)
+	assert.Equal(t, "pets", v.Item[0].Name)
+	assert.Equal(t, "health check", v.Item[1].Name)
+
+	put := v.Item[0].Item[2].Request
+	assert.Equal(t, "{{baseUrl}}/pets/:id", put.URL.Raw)
+	assert.Equal(t, []string{"pets", ":id"}, put.URL.Path)
+	assert.Equal(t, "{\n  \"id\": 1,\n  \"name\": \"Fido\"\n}", put.Body.Raw)
+	assert.Equal(t, "bearer", put.Auth.Type)
+
+	upload := v.Item[0].Item[4].Request
+	assert.Equal(t, "formdata", upload.Body.Mode)
+	assert.Equal(t, "file", upload.Body.FormData[1].Type)
+}
+
+func TestInsomnia(t *testing.T) {
+	data, err := Insomnia(petstore(), BaseURL("http://localhost:8080/v1"))
+	assert.Nil(t, err)
+	golden(t, "petstore.insomnia.json", data)
+
+	v := insomniaExport{}
+	assert.Nil(t, json.Unmarshal(data, &v))
+	assert.Equal(t, 4, v.Format)
+	assert.Equal(t, "workspace", v.Resources[0].Type)
+	assert.Equal(t, "http://localhost:8080/v1", v.Resources[1].Data["baseUrl"])
+	assert.Contains(t, v.Resources[1].Data, "basic_password")
+
+	// ids are stable across exports
+	again, err := Insomnia(petstore(), BaseURL("http://localhost:8080/v1"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestFlow(t *testing.T) {
+	scheme := swagger.SecurityScheme{
+		Type: "oauth2",
+		Flows: map[string]swagger.OAuthFlow{
+			"clientCredentials": {TokenURL: "https://auth.example.com/token"},
+			"implicit":          {AuthorizationURL: "https://auth.example.com/authorize"},
+		},
+	}
+	assert.Equal(t, oauthFlow{GrantType: "client_credentials", TokenURL: "https://auth.example.com/token"}, flow(scheme))
+
+	assert.Equal(t, "password", flow(swagger.SecurityScheme{Type: "oauth2", Flow: "password"}).GrantType)
+}
+
+func TestSnippetCurl(t *testing.T) {
+	api := petstore()
+
+	s, err := Snippet(api, "listPets", Curl)
+	assert.Nil(t, err)
+	assert.Equal(t, `curl -g -X GET 'https://api.example.com/v1/pets?status={status}' \
+  -H "X-API-Key: $API_KEY"
+`, s)
+
+	s, err = Snippet(api, "replacePet", Curl, BaseURL("http://localhost:8080"))
+	assert.Nil(t, err)
+	assert.Equal(t, `curl -g -X PUT 'http://localhost:8080/pets/{id}' \
+  -H 'Content-Type: application/json' \
+  -H "Authorization: Bearer $JWT_TOKEN" \
+  -d '{
+  "id": 1,
+  "name": "Fido"
+}'
+`, s)
+
+	s, err = Snippet(api, "uploadPhoto", Curl)
+	assert.Nil(t, err)
+	assert.Equal(t, `curl -g -X POST 'https://api.example.com/v1/pets/{id}/photo' \
+  -u "$BASIC_USERNAME:$BASIC_PASSWORD" \
+  -F 'photo=@{photo}'
+`, s)
+
+	s, err = Snippet(api, "health", Curl)
+	assert.Nil(t, err)
+	assert.Equal(t, `curl -g -X GET 'https://api.example.com/v1/health' \
+  -b "session=$SESSION"
+`, s)
+
+	s, err = Snippet(api, "deletePet", Curl)
+	assert.Nil(t, err)
+	assert.Equal(t, "curl -g -X DELETE 'https://api.example.com/v1/pets/{id}'\n", s)
+}
+
+func TestSnippetHTTPie(t *testing.T) {
+	api := petstore()
+
+	s, err := Snippet(api, "listPets", HTTPie)
+	assert.Nil(t, err)
+	assert.Equal(t, `http GET 'https://api.example.com/v1/pets' \
+  'status=={status}' \
+  "X-API-Key:$API_KEY"
+`, s)
+
+	s, err = Snippet(api, "createPet", HTTPie)
+	assert.Nil(t, err)
+	assert.Equal(t, `echo '{
+  "id": 0,
+  "name": "Rex",
+  "status": "available",
+  "tags": [
+    "string"
+  ]
+}' | http POST 'https://api.example.com/v1/pets' \
+  'Content-Type:application/json' \
+  "Authorization:Bearer $OAUTH_TOKEN"
+`, s)
+
+	s, err = Snippet(api, "uploadPhoto", HTTPie)
+	assert.Nil(t, err)
+	assert.Equal(t, `http --form -a "$BASIC_USERNAME:$BASIC_PASSWORD" POST 'https://api.example.com/v1/pets/{id}/photo' \
+  'photo@{photo}'
+`, s)
+}
+
+func TestSnippetGo(t *testing.T) {
+	api := petstore()
+	for _, id := range []string{"createPet", "uploadPhoto"} {
+		s, err := Snippet(api, id, Go)
+		assert.Nil(t, err)
+		golden(t, id+".go.golden", []byte(s))
+	}
+}
+
+func TestSnippetCombinedSecurity(t *testing.T) {
+	api := petstore()
+	api.SecurityDefinitions["tenant"] = swagger.SecurityScheme{Type: "apiKey", Name: "tenant", In: "query"}
+	api.SecurityDefinitions["token"] = swagger.SecurityScheme{Type: "apiKey", Name: "token", In: "query"}
+	api.AddEndpoint(&swagger.Endpoint{
+		Method:      "GET",
+		Path:        "/stores",
+		OperationID: "listStores",
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{
+			{"token": {}, "tenant": {}, "session": {}},
+			{"basic": {}},
+		}},
+	})
+
+	// every scheme of the first alternative is sent
+	s, err := Snippet(api, "listStores", Curl)
+	assert.Nil(t, err)
+	assert.Equal(t, `curl -g -X GET "https://api.example.com/v1/stores?tenant=$TENANT&token=$TOKEN" \
+  -b "session=$SESSION"
+`, s)
+
+	s, err = Snippet(api, "listStores", Go)
+	assert.Nil(t, err)
+	assert.Contains(t, s, `"https://api.example.com/v1/stores?tenant="+url.QueryEscape(os.Getenv("TENANT"))+"&token="+url.QueryEscape(os.Getenv("TOKEN"))`)
+
+	// postman attaches a single auth, so the alternative made of one scheme is preferred
+	assert.Equal(t, "basic", postmanAuthOf(api, api.Paths["/stores"].Get).Type)
+}
+
+func TestSnippetErrors(t *testing.T) {
+	_, err := Snippet(petstore(), "listPets", "powershell")
+	assert.NotNil(t, err)
+
+	_, err = Snippet(petstore(), "missing", Curl)
+	assert.NotNil(t, err)
+}
+
+func TestEnvironmentVariable(t *testing.T) {
+	assert.Equal(t, "API_KEY", environmentVariable("api_key", ""))
+	assert.Equal(t, "PETSTORE_AUTH_TOKEN", environmentVariable("petstore-auth", "TOKEN"))
+}
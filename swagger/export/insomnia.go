@@ -0,0 +1,223 @@
+package export
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+type insomniaExport struct {
+	Type      string             `json:"_type"`
+	Format    int                `json:"__export_format"`
+	Source    string             `json:"__export_source"`
+	Resources []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a workspace, environment, request group or request as selected by Type
+type insomniaResource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       *string                `json:"parentId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	Scope          string                 `json:"scope,omitempty"`
+	Data           map[string]string      `json:"data,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	URL            string                 `json:"url,omitempty"`
+	Parameters     []insomniaValue        `json:"parameters,omitempty"`
+	PathParameters []insomniaValue        `json:"pathParameters,omitempty"`
+	Headers        []insomniaValue        `json:"headers,omitempty"`
+	Body           *insomniaBody          `json:"body,omitempty"`
+	Authentication map[string]interface{} `json:"authentication,omitempty"`
+}
+
+type insomniaValue struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type insomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text,omitempty"`
+	Params   []insomniaValue `json:"params,omitempty"`
+}
+
+// Insomnia exports the api as an Insomnia v4 export holding a workspace with a folder per tag and a request per
+// endpoint.  The base environment defines baseUrl along with a variable per credential named after its security
+// scheme; request bodies are synthesized from the body schema unless an example is declared
+func Insomnia(api *swagger.API, options ...Option) ([]byte, error) {
+	api = api.Snapshot()
+	c := newConfig(api, options)
+
+	name := collectionName(api)
+
+	workspaceID := insomniaID("wrk", name)
+	workspace := insomniaResource{
+		ID:          workspaceID,
+		Type:        "workspace",
+		Name:        name,
+		Description: api.Info.Description,
+		Scope:       "collection",
+	}
+
+	environment := insomniaResource{
+		ID:       insomniaID("env", name),
+		Type:     "environment",
+		ParentID: &workspaceID,
+		Name:     "Base Environment",
+		Data:     map[string]string{baseURLVariable: c.baseURL},
+	}
+	for _, name := range schemeNames(api) {
+		for _, variable := range credentials(name, api.SecurityDefinitions[name]) {
+			environment.Data[variable] = ""
+		}
+	}
+
+	export := insomniaExport{
+		Type:      "export",
+		Format:    4,
+		Source:    "docs",
+		Resources: []insomniaResource{workspace, environment},
+	}
+
+	ops := api.Operations()
+	groups, untagged := folders(api, ops)
+	for _, f := range groups {
+		folderID := insomniaID("fld", workspaceID, f.Name)
+		export.Resources = append(export.Resources, insomniaResource{
+			ID:          folderID,
+			Type:        "request_group",
+			ParentID:    &workspaceID,
+			Name:        f.Name,
+			Description: f.Description,
+		})
+		for _, op := range f.Operations {
+			export.Resources = append(export.Resources, insomniaRequest(api, op, workspaceID, folderID))
+		}
+	}
+	for _, op := range untagged {
+		export.Resources = append(export.Resources, insomniaRequest(api, op, workspaceID, workspaceID))
+	}
+
+	return marshal(export)
+}
+
+// insomniaID returns an id that is stable across exports so importing again updates rather than duplicates resources
+func insomniaID(prefix string, values ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(values, " ")))
+	return prefix + "_" + hex.EncodeToString(sum[:16])
+}
+
+// insomniaVariable returns the template expression referring to the environment variable
+func insomniaVariable(name string) string {
+	return "{{ _." + name + " }}"
+}
+
+func insomniaRequest(api *swagger.API, op swagger.Operation, workspaceID, parentID string) insomniaResource {
+	e := op.Endpoint
+	r := insomniaResource{
+		ID:          insomniaID("req", workspaceID, op.Method, op.Path),
+		Type:        "request",
+		ParentID:    &parentID,
+		Name:        requestName(op),
+		Description: e.Description,
+		Method:      op.Method,
+		URL:         insomniaVariable(baseURLVariable) + pathVariables(op.Path),
+	}
+
+	var form []insomniaValue
+	multipart := strings.HasPrefix(contentType(e), "multipart/")
+	for _, p := range e.Parameters {
+		value := insomniaValue{Name: p.Name, Value: parameterValue(p), Description: p.Description}
+		switch p.In {
+		case "path":
+			r.PathParameters = append(r.PathParameters, value)
+		case "query":
+			value.Disabled = !p.Required
+			r.Parameters = append(r.Parameters, value)
+		case "header":
+			value.Disabled = !p.Required
+			r.Headers = append(r.Headers, value)
+		case "formData":
+			value.Disabled = !p.Required
+			if p.Type == "file" {
+				value.Type, value.Value, multipart = "file", "", true
+			}
+			form = append(form, value)
+		}
+	}
+
+	if body, ok := bodyExample(api, e); ok {
+		r.Headers = append(r.Headers, insomniaValue{Name: "Content-Type", Value: contentType(e)})
+		r.Body = &insomniaBody{MimeType: contentType(e), Text: body}
+	} else if multipart {
+		r.Headers = append(r.Headers, insomniaValue{Name: "Content-Type", Value: "multipart/form-data"})
+		r.Body = &insomniaBody{MimeType: "multipart/form-data", Params: form}
+	} else if len(form) > 0 {
+		r.Headers = append(r.Headers, insomniaValue{Name: "Content-Type", Value: "application/x-www-form-urlencoded"})
+		r.Body = &insomniaBody{MimeType: "application/x-www-form-urlencoded", Params: form}
+	}
+
+	r.Authentication = insomniaAuthentication(api, e)
+
+	return r
+}
+
+// insomniaAuthentication returns the authentication of requests to the endpoint; nil if they aren't authenticated
+func insomniaAuthentication(api *swagger.API, e *swagger.Endpoint) map[string]interface{} {
+	auth, ok := singleAuth(api, e)
+	if !ok {
+		return nil
+	}
+	name, scheme, scopes := auth.Name, auth.Scheme, auth.Scopes
+
+	variables := credentials(name, scheme)
+	switch scheme.Kind() {
+	case "basic":
+		return map[string]interface{}{
+			"type":     "basic",
+			"username": insomniaVariable(variables[0]),
+			"password": insomniaVariable(variables[1]),
+		}
+
+	case "bearer":
+		return map[string]interface{}{
+			"type":  "bearer",
+			"token": insomniaVariable(variables[0]),
+		}
+
+	case "apiKey":
+		addTo := "header"
+		switch scheme.In {
+		case "query":
+			addTo = "queryParams"
+		case "cookie":
+			addTo = "cookie"
+		}
+		return map[string]interface{}{
+			"type":  "apikey",
+			"key":   scheme.Name,
+			"value": insomniaVariable(variables[0]),
+			"addTo": addTo,
+		}
+
+	case "oauth2":
+		f := flow(scheme)
+		return map[string]interface{}{
+			"type":             "oauth2",
+			"grantType":        f.GrantType,
+			"authorizationUrl": f.AuthorizationURL,
+			"accessTokenUrl":   f.TokenURL,
+			"clientId":         insomniaVariable(variables[0]),
+			"clientSecret":     insomniaVariable(variables[1]),
+			"scope":            strings.Join(scopes, " "),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package export
+
+import (
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// postmanSchema identifies the version of the collection format
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// postmanItem is either a folder of items or a request
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []postmanItem   `json:"item,omitempty"`
+	Request     *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method      string         `json:"method"`
+	Header      []postmanValue `json:"header"`
+	URL         postmanURL     `json:"url"`
+	Body        *postmanBody   `json:"body,omitempty"`
+	Auth        *postmanAuth   `json:"auth,omitempty"`
+	Description string         `json:"description,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string         `json:"raw"`
+	Host     []string       `json:"host"`
+	Path     []string       `json:"path,omitempty"`
+	Query    []postmanValue `json:"query,omitempty"`
+	Variable []postmanValue `json:"variable,omitempty"`
+}
+
+type postmanValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type postmanBody struct {
+	Mode       string                 `json:"mode"`
+	Raw        string                 `json:"raw,omitempty"`
+	URLEncoded []postmanValue         `json:"urlencoded,omitempty"`
+	FormData   []postmanValue         `json:"formdata,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+// postmanAuth holds the settings of the auth type in the field named by Type
+type postmanAuth struct {
+	Type   string         `json:"type"`
+	APIKey []postmanValue `json:"apikey,omitempty"`
+	Basic  []postmanValue `json:"basic,omitempty"`
+	Bearer []postmanValue `json:"bearer,omitempty"`
+	OAuth2 []postmanValue `json:"oauth2,omitempty"`
+}
+
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// Postman exports the api as a Postman Collection v2.1 with a folder per tag and a request per endpoint.  Request urls
+// start with the {{baseUrl}} collection variable, credentials refer to collection variables named after their
+// security scheme and request bodies are synthesized from the body schema unless an example is declared
+func Postman(api *swagger.API, options ...Option) ([]byte, error) {
+	api = api.Snapshot()
+	c := newConfig(api, options)
+
+	collection := postmanCollection{
+		Info: postmanInfo{
+			Name:        collectionName(api),
+			Description: api.Info.Description,
+			Schema:      postmanSchema,
+		},
+		Item: []postmanItem{},
+		Variable: []postmanVariable{
+			{Key: baseURLVariable, Value: c.baseURL, Type: "string"},
+		},
+	}
+
+	for _, name := range schemeNames(api) {
+		for _, variable := range credentials(name, api.SecurityDefinitions[name]) {
+			collection.Variable = append(collection.Variable, postmanVariable{Key: variable, Value: "", Type: "string"})
+		}
+	}
+
+	if api.Security != nil {
+		collection.Auth = postmanAuthOf(api, &swagger.Endpoint{})
+	}
+
+	ops := api.Operations()
+	groups, untagged := folders(api, ops)
+	for _, f := range groups {
+		item := postmanItem{Name: f.Name, Description: f.Description, Item: []postmanItem{}}
+		for _, op := range f.Operations {
+			item.Item = append(item.Item, postmanRequestItem(api, op))
+		}
+		collection.Item = append(collection.Item, item)
+	}
+	for _, op := range untagged {
+		collection.Item = append(collection.Item, postmanRequestItem(api, op))
+	}
+
+	return marshal(collection)
+}
+
+func postmanRequestItem(api *swagger.API, op swagger.Operation) postmanItem {
+	e := op.Endpoint
+	rawPath := pathVariables(op.Path)
+
+	r := &postmanRequest{
+		Method: op.Method,
+		Header: []postmanValue{},
+		URL: postmanURL{
+			Raw:  "{{" + baseURLVariable + "}}" + rawPath,
+			Host: []string{"{{" + baseURLVariable + "}}"},
+			Path: strings.Split(strings.Trim(rawPath, "/"), "/"),
+		},
+		Description: e.Description,
+	}
+	if len(r.URL.Path) == 1 && r.URL.Path[0] == "" {
+		r.URL.Path = nil
+	}
+
+	var query []string
+	var form []postmanValue
+	multipart := strings.HasPrefix(contentType(e), "multipart/")
+	for _, p := range e.Parameters {
+		value := postmanValue{Key: p.Name, Value: parameterValue(p), Description: p.Description}
+		switch p.In {
+		case "path":
+			r.URL.Variable = append(r.URL.Variable, value)
+		case "query":
+			value.Disabled = !p.Required
+			r.URL.Query = append(r.URL.Query, value)
+			if p.Required {
+				query = append(query, p.Name+"="+value.Value)
+			}
+		case "header":
+			value.Disabled = !p.Required
+			r.Header = append(r.Header, value)
+		case "formData":
+			value.Type, value.Disabled = "text", !p.Required
+			if p.Type == "file" {
+				value.Type, value.Value, multipart = "file", "", true
+			}
+			form = append(form, value)
+		}
+	}
+	if len(query) > 0 {
+		r.URL.Raw += "?" + strings.Join(query, "&")
+	}
+
+	if body, ok := bodyExample(api, e); ok {
+		r.Header = append(r.Header, postmanValue{Key: "Content-Type", Value: contentType(e)})
+		r.Body = &postmanBody{Mode: "raw", Raw: body}
+		if strings.Contains(contentType(e), "json") {
+			r.Body.Options = map[string]interface{}{"raw": map[string]string{"language": "json"}}
+		}
+	} else if multipart {
+		// postman sets the multipart content type along with its boundary
+		r.Body = &postmanBody{Mode: "formdata", FormData: form}
+	} else if len(form) > 0 {
+		for i := range form {
+			form[i].Type = ""
+		}
+		r.Header = append(r.Header, postmanValue{Key: "Content-Type", Value: "application/x-www-form-urlencoded"})
+		r.Body = &postmanBody{Mode: "urlencoded", URLEncoded: form}
+	}
+
+	if e.Security != nil {
+		r.Auth = postmanAuthOf(api, e)
+	}
+
+	return postmanItem{Name: requestName(op), Request: r}
+}
+
+// postmanAuthOf returns the auth of requests to the endpoint; noauth if the endpoint isn't authenticated
+func postmanAuthOf(api *swagger.API, e *swagger.Endpoint) *postmanAuth {
+	auth, ok := singleAuth(api, e)
+	if !ok {
+		return &postmanAuth{Type: "noauth"}
+	}
+	name, scheme, scopes := auth.Name, auth.Scheme, auth.Scopes
+
+	variables := credentials(name, scheme)
+	ref := func(i int) string {
+		return "{{" + variables[i] + "}}"
+	}
+
+	switch scheme.Kind() {
+	case "basic":
+		return &postmanAuth{Type: "basic", Basic: []postmanValue{
+			{Key: "username", Value: ref(0), Type: "string"},
+			{Key: "password", Value: ref(1), Type: "string"},
+		}}
+
+	case "bearer":
+		return &postmanAuth{Type: "bearer", Bearer: []postmanValue{
+			{Key: "token", Value: ref(0), Type: "string"},
+		}}
+
+	case "apiKey":
+		// postman can't add api keys to cookies, so the cookie header is set instead
+		key, value, in := scheme.Name, ref(0), scheme.In
+		if in == "cookie" {
+			key, value, in = "Cookie", scheme.Name+"="+ref(0), "header"
+		}
+		return &postmanAuth{Type: "apikey", APIKey: []postmanValue{
+			{Key: "key", Value: key, Type: "string"},
+			{Key: "value", Value: value, Type: "string"},
+			{Key: "in", Value: in, Type: "string"},
+		}}
+
+	case "oauth2":
+		f := flow(scheme)
+		grantType := f.GrantType
+		if grantType == "password" {
+			grantType = "password_credentials"
+		}
+		return &postmanAuth{Type: "oauth2", OAuth2: []postmanValue{
+			{Key: "grant_type", Value: grantType, Type: "string"},
+			{Key: "authUrl", Value: f.AuthorizationURL, Type: "string"},
+			{Key: "accessTokenUrl", Value: f.TokenURL, Type: "string"},
+			{Key: "clientId", Value: ref(0), Type: "string"},
+			{Key: "clientSecret", Value: ref(1), Type: "string"},
+			{Key: "scope", Value: strings.Join(scopes, " "), Type: "string"},
+			{Key: "addTokenTo", Value: "header", Type: "string"},
+		}}
+	}
+
+	return &postmanAuth{Type: "noauth"}
+}
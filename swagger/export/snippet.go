@@ -0,0 +1,462 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// snippet languages supported by Snippet
+const (
+	Curl   = "curl"
+	HTTPie = "httpie"
+	Go     = "go"
+)
+
+var snippetGenerators = map[string]func(r *snippetRequest) (string, error){
+	Curl:   curlSnippet,
+	HTTPie: httpieSnippet,
+	Go:     goSnippet,
+}
+
+// Snippet renders a copy-paste command, or program for Go, calling the operation with the specified operationId.  The
+// url is the baseUrl followed by the path with {name} placeholders for path and required query parameters, credentials
+// are read from environment variables named after the security scheme e.g. $API_KEY or $JWT_TOKEN, and the body is
+// synthesized from the body schema unless an example is declared
+func Snippet(api *swagger.API, operationID, lang string, options ...Option) (string, error) {
+	generate, ok := snippetGenerators[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported snippet language, %v", lang)
+	}
+
+	api = api.Snapshot()
+	c := newConfig(api, options)
+
+	for _, op := range api.Operations() {
+		if op.Endpoint.OperationID == operationID {
+			return generate(newSnippetRequest(api, c, op))
+		}
+	}
+
+	return "", fmt.Errorf("no operation with operationId, %v", operationID)
+}
+
+type snippetValue struct {
+	Name  string
+	Value string
+}
+
+type snippetField struct {
+	Name  string
+	Value string
+	File  bool
+}
+
+// snippetCredential describes where a credential read from environment variables goes
+type snippetCredential struct {
+	// In is header, query, cookie or basic
+	In string
+
+	// Name of the header, query parameter or cookie
+	Name string
+
+	// Prefix precedes the variable in the value e.g. Bearer
+	Prefix string
+
+	// Variables are the environment variables holding the credential; the username and password for basic
+	Variables []string
+}
+
+type snippetRequest struct {
+	Method      string
+	URL         string
+	Query       []snippetValue
+	Headers     []snippetValue
+	Form        []snippetField
+	Multipart   bool
+	ContentType string
+	Body        string
+	Credentials []*snippetCredential
+}
+
+func newSnippetRequest(api *swagger.API, c *config, op swagger.Operation) *snippetRequest {
+	e := op.Endpoint
+	r := &snippetRequest{
+		Method: op.Method,
+		URL:    c.baseURL + op.Path,
+	}
+
+	for _, p := range e.Parameters {
+		if !p.Required {
+			continue
+		}
+		placeholder := "{" + p.Name + "}"
+		switch p.In {
+		case "query":
+			r.Query = append(r.Query, snippetValue{Name: p.Name, Value: placeholder})
+		case "header":
+			r.Headers = append(r.Headers, snippetValue{Name: p.Name, Value: placeholder})
+		case "formData":
+			r.Form = append(r.Form, snippetField{Name: p.Name, Value: placeholder, File: p.Type == "file"})
+			r.Multipart = r.Multipart || p.Type == "file"
+		}
+	}
+
+	if body, ok := bodyExample(api, e); ok {
+		r.ContentType, r.Body = contentType(e), body
+	} else if r.Multipart || strings.HasPrefix(contentType(e), "multipart/") {
+		r.Multipart = len(r.Form) > 0
+	} else if len(r.Form) > 0 {
+		r.ContentType = "application/x-www-form-urlencoded"
+	}
+
+	// every scheme of the first alternative is required
+	if alternatives := security(api, e); len(alternatives) > 0 {
+		for _, auth := range alternatives[0] {
+			if credential := snippetCredentialOf(auth); credential != nil {
+				r.Credentials = append(r.Credentials, credential)
+			}
+		}
+	}
+
+	return r
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// environmentVariable returns the name of the environment variable holding a credential of the scheme e.g. jwt, TOKEN
+// => JWT_TOKEN
+func environmentVariable(scheme, suffix string) string {
+	name := strings.Trim(nonIdentifier.ReplaceAllString(scheme, "_"), "_")
+	if suffix != "" {
+		name += "_" + suffix
+	}
+	return strings.ToUpper(name)
+}
+
+func snippetCredentialOf(auth authScheme) *snippetCredential {
+	name, scheme := auth.Name, auth.Scheme
+	switch scheme.Kind() {
+	case "basic":
+		return &snippetCredential{
+			In:        "basic",
+			Variables: []string{environmentVariable(name, "USERNAME"), environmentVariable(name, "PASSWORD")},
+		}
+
+	case "apiKey":
+		return &snippetCredential{
+			In:        scheme.In,
+			Name:      scheme.Name,
+			Variables: []string{environmentVariable(name, "")},
+		}
+
+	case "bearer", "oauth2":
+		return &snippetCredential{
+			In:        "header",
+			Name:      "Authorization",
+			Prefix:    "Bearer ",
+			Variables: []string{environmentVariable(name, "TOKEN")},
+		}
+	}
+
+	return nil
+}
+
+// singleQuote quotes v for a posix shell without expanding anything
+func singleQuote(v string) string {
+	return "'" + strings.Replace(v, "'", `'\''`, -1) + "'"
+}
+
+// doubleQuote quotes prefix for a posix shell followed by the expansion of the environment variable; more pairs of
+// text and variables may follow
+func doubleQuote(prefix, variable string, more ...string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	quoted := `"` + r.Replace(prefix) + "$" + variable
+	for i := 0; i+1 < len(more); i += 2 {
+		quoted += r.Replace(more[i]) + "$" + more[i+1]
+	}
+	return quoted + `"`
+}
+
+// credentialsIn returns the credentials of the request that go in the header, query, cookie or basic
+func (r *snippetRequest) credentialsIn(in string) []*snippetCredential {
+	var credentials []*snippetCredential
+	for _, c := range r.Credentials {
+		if c.In == in {
+			credentials = append(credentials, c)
+		}
+	}
+	return credentials
+}
+
+// rawQuery joins the query parameters without escaping so placeholders remain readable
+func rawQuery(values []snippetValue) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, url.QueryEscape(v.Name)+"="+v.Value)
+	}
+	return strings.Join(parts, "&")
+}
+
+// shellURL returns the quoted url with the query parameters, including api keys passed in the query
+func (r *snippetRequest) shellURL() string {
+	u := r.URL
+	if len(r.Query) > 0 {
+		u += "?" + rawQuery(r.Query)
+	}
+
+	keys := r.credentialsIn("query")
+	if len(keys) == 0 {
+		return singleQuote(u)
+	}
+
+	separator := "?"
+	if len(r.Query) > 0 {
+		separator = "&"
+	}
+	var more []string
+	for _, c := range keys[1:] {
+		more = append(more, "&"+url.QueryEscape(c.Name)+"=", c.Variables[0])
+	}
+	return doubleQuote(u+separator+url.QueryEscape(keys[0].Name)+"=", keys[0].Variables[0], more...)
+}
+
+// curlSnippet renders a curl command; -g turns off curl's url globbing, which would otherwise reject or expand the
+// {name} placeholders
+func curlSnippet(r *snippetRequest) (string, error) {
+	lines := []string{"curl -g -X " + r.Method + " " + r.shellURL()}
+
+	for _, h := range r.Headers {
+		lines = append(lines, "-H "+singleQuote(h.Name+": "+h.Value))
+	}
+	if r.Body != "" {
+		lines = append(lines, "-H "+singleQuote("Content-Type: "+r.ContentType))
+	}
+
+	for _, c := range r.Credentials {
+		switch c.In {
+		case "header":
+			lines = append(lines, "-H "+doubleQuote(c.Name+": "+c.Prefix, c.Variables[0]))
+		case "cookie":
+			lines = append(lines, "-b "+doubleQuote(c.Name+"=", c.Variables[0]))
+		case "basic":
+			lines = append(lines, `-u "$`+c.Variables[0]+`:$`+c.Variables[1]+`"`)
+		}
+	}
+
+	for _, f := range r.Form {
+		switch {
+		case f.File:
+			lines = append(lines, "-F "+singleQuote(f.Name+"=@"+f.Value))
+		case r.Multipart:
+			lines = append(lines, "-F "+singleQuote(f.Name+"="+f.Value))
+		default:
+			lines = append(lines, "--data-urlencode "+singleQuote(f.Name+"="+f.Value))
+		}
+	}
+
+	if r.Body != "" {
+		lines = append(lines, "-d "+singleQuote(r.Body))
+	}
+
+	return strings.Join(lines, " \\\n  ") + "\n", nil
+}
+
+func httpieSnippet(r *snippetRequest) (string, error) {
+	var prefix string
+	if r.Body != "" {
+		prefix = "echo " + singleQuote(r.Body) + " | "
+	}
+
+	// httpie switches to multipart by itself when a file is attached
+	command := "http"
+	if len(r.Form) > 0 {
+		command += " --form"
+	}
+	for _, c := range r.credentialsIn("basic") {
+		command += ` -a "$` + c.Variables[0] + `:$` + c.Variables[1] + `"`
+	}
+
+	lines := []string{prefix + command + " " + r.Method + " " + singleQuote(r.URL)}
+
+	for _, q := range r.Query {
+		lines = append(lines, singleQuote(q.Name+"=="+q.Value))
+	}
+	for _, h := range r.Headers {
+		lines = append(lines, singleQuote(h.Name+":"+h.Value))
+	}
+	if r.Body != "" {
+		lines = append(lines, singleQuote("Content-Type:"+r.ContentType))
+	}
+
+	for _, c := range r.Credentials {
+		switch c.In {
+		case "header":
+			lines = append(lines, doubleQuote(c.Name+":"+c.Prefix, c.Variables[0]))
+		case "query":
+			lines = append(lines, doubleQuote(c.Name+"==", c.Variables[0]))
+		case "cookie":
+			lines = append(lines, doubleQuote("Cookie:"+c.Name+"=", c.Variables[0]))
+		}
+	}
+
+	for _, f := range r.Form {
+		if f.File {
+			lines = append(lines, singleQuote(f.Name+"@"+f.Value))
+			continue
+		}
+		lines = append(lines, singleQuote(f.Name+"="+f.Value))
+	}
+
+	return strings.Join(lines, " \\\n  ") + "\n", nil
+}
+
+// goString returns v as a go string literal, preferring a raw string for multi-line values
+func goString(v string) string {
+	if strings.Contains(v, "\n") && !strings.Contains(v, "`") {
+		return "`" + v + "`"
+	}
+	return strconv.Quote(v)
+}
+
+func goSnippet(r *snippetRequest) (string, error) {
+	imports := map[string]bool{"fmt": true, "io/ioutil": true, "net/http": true}
+	body := &bytes.Buffer{}
+	printf := func(format string, args ...interface{}) {
+		fmt.Fprintf(body, format, args...)
+	}
+
+	target := goString(r.URL)
+	if len(r.Query) > 0 {
+		target = goString(r.URL + "?" + rawQuery(r.Query))
+	}
+	for i, c := range r.credentialsIn("query") {
+		imports["net/url"], imports["os"] = true, true
+		value := url.QueryEscape(c.Name) + `=" + url.QueryEscape(os.Getenv("` + c.Variables[0] + `"))`
+		switch {
+		case i > 0:
+			target += ` + "&` + value
+		case len(r.Query) > 0:
+			target = strings.TrimSuffix(target, `"`) + "&" + value
+		default:
+			target = strings.TrimSuffix(target, `"`) + "?" + value
+		}
+	}
+
+	reader := "nil"
+	switch {
+	case r.Body != "":
+		imports["strings"] = true
+		printf("\tbody := strings.NewReader(%v)\n", goString(r.Body))
+		reader = "body"
+
+	case r.Multipart:
+		imports["bytes"], imports["mime/multipart"] = true, true
+		printf("\tbody := &bytes.Buffer{}\n")
+		printf("\tform := multipart.NewWriter(body)\n")
+		for _, f := range r.Form {
+			if !f.File {
+				printf("\tform.WriteField(%q, %q)\n", f.Name, f.Value)
+				continue
+			}
+			imports["io"], imports["os"], imports["path/filepath"] = true, true, true
+			printf("\n\t%vFile, err := os.Open(%q)\n", unexportedName(f.Name), f.Value)
+			printf("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+			printf("\tdefer %vFile.Close()\n", unexportedName(f.Name))
+			printf("\t%vPart, err := form.CreateFormFile(%q, filepath.Base(%vFile.Name()))\n", unexportedName(f.Name), f.Name, unexportedName(f.Name))
+			printf("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+			printf("\tif _, err := io.Copy(%vPart, %vFile); err != nil {\n\t\tpanic(err)\n\t}\n\n", unexportedName(f.Name), unexportedName(f.Name))
+		}
+		printf("\tform.Close()\n\n")
+		reader = "body"
+
+	case len(r.Form) > 0:
+		imports["net/url"], imports["strings"] = true, true
+		printf("\tform := url.Values{}\n")
+		for _, f := range r.Form {
+			printf("\tform.Set(%q, %q)\n", f.Name, f.Value)
+		}
+		printf("\tbody := strings.NewReader(form.Encode())\n")
+		reader = "body"
+	}
+
+	printf("\treq, err := http.NewRequest(%q, %v, %v)\n", r.Method, target, reader)
+	printf("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+
+	for _, h := range r.Headers {
+		printf("\treq.Header.Set(%q, %q)\n", h.Name, h.Value)
+	}
+	switch {
+	case r.Multipart:
+		printf("\treq.Header.Set(\"Content-Type\", form.FormDataContentType())\n")
+	case r.ContentType != "":
+		printf("\treq.Header.Set(\"Content-Type\", %q)\n", r.ContentType)
+	}
+
+	for _, c := range r.Credentials {
+		imports["os"] = true
+		switch c.In {
+		case "header":
+			value := "os.Getenv(" + strconv.Quote(c.Variables[0]) + ")"
+			if c.Prefix != "" {
+				value = strconv.Quote(c.Prefix) + "+" + value
+			}
+			printf("\treq.Header.Set(%q, %v)\n", c.Name, value)
+		case "cookie":
+			printf("\treq.AddCookie(&http.Cookie{Name: %q, Value: os.Getenv(%q)})\n", c.Name, c.Variables[0])
+		case "basic":
+			printf("\treq.SetBasicAuth(os.Getenv(%q), os.Getenv(%q))\n", c.Variables[0], c.Variables[1])
+		}
+	}
+
+	printf("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	printf("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	printf("\tdefer resp.Body.Close()\n\n")
+	printf("\tdata, err := ioutil.ReadAll(resp.Body)\n")
+	printf("\tif err != nil {\n\t\tpanic(err)\n\t}\n\n")
+	printf("\tfmt.Println(resp.Status)\n")
+	printf("\tfmt.Println(string(data))\n")
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "package main\n\nimport (\n")
+	for _, name := range names {
+		fmt.Fprintf(src, "\t%q\n", name)
+	}
+	fmt.Fprintf(src, ")\n\nfunc main() {\n%v}\n", body.String())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("unable to format go snippet: %v", err)
+	}
+	return string(formatted), nil
+}
+
+// unexportedName converts v into an unexported go identifier e.g. profile_photo => profilePhoto
+func unexportedName(v string) string {
+	words := strings.Fields(nonIdentifier.ReplaceAllString(v, " "))
+	b := &strings.Builder{}
+	for i, word := range words {
+		if i == 0 {
+			b.WriteString(strings.ToLower(word[:1]) + word[1:])
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if b.Len() == 0 || (b.String()[0] >= '0' && b.String()[0] <= '9') {
+		return "x" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidateExtension panics if name is not a valid vendor extension name; extensions must begin with x-
+func ValidateExtension(name string) {
+	if !strings.HasPrefix(name, "x-") {
+		panic(fmt.Errorf(`extension %q must begin with "x-"`, name))
+	}
+}
+
+// Extender is implemented by types that add vendor extensions to the definitions generated for them
+type Extender interface {
+	SwaggerExtensions() map[string]interface{}
+}
+
+// typeExtensions returns a copy of the vendor extensions of t, or nil if t doesn't implement Extender; the method is
+// called on the zero value of t, so it may have either a value or a pointer receiver
+func typeExtensions(t reflect.Type) map[string]interface{} {
+	extender, ok := reflect.New(t).Interface().(Extender)
+	if !ok {
+		return nil
+	}
+
+	var extensions map[string]interface{}
+	for name, value := range extender.SwaggerExtensions() {
+		ValidateExtension(name)
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[name] = value
+	}
+	return extensions
+}
+
+// marshalExtensions marshals v, which must marshal to a json object, and inlines the extensions into the result
+func marshalExtensions(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for i, name := range names {
+		value, err := json.Marshal(extensions[name])
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 || len(data) > 2 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalExtensions unmarshals data into v and returns the x- members of the json object that aren't already
+// mapped to one of v's fields
+func unmarshalExtensions(data []byte, v interface{}) (map[string]interface{}, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		known[name] = true
+	}
+
+	var extensions map[string]interface{}
+	for name, raw := range members {
+		if !strings.HasPrefix(name, "x-") || known[name] {
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[name] = value
+	}
+
+	return extensions, nil
+}
+
+type jsonAPI API
+
+// MarshalJSON inlines the api's vendor extensions
+func (a API) MarshalJSON() ([]byte, error) {
+	return marshalExtensions((*jsonAPI)(&a), a.Extensions)
+}
+
+// UnmarshalJSON collects the api's vendor extensions
+func (a *API) UnmarshalJSON(data []byte) (err error) {
+	a.Extensions, err = unmarshalExtensions(data, (*jsonAPI)(a))
+	return err
+}
+
+type jsonInfo Info
+
+// MarshalJSON inlines the info's vendor extensions
+func (i Info) MarshalJSON() ([]byte, error) {
+	return marshalExtensions(jsonInfo(i), i.Extensions)
+}
+
+// UnmarshalJSON collects the info's vendor extensions
+func (i *Info) UnmarshalJSON(data []byte) (err error) {
+	i.Extensions, err = unmarshalExtensions(data, (*jsonInfo)(i))
+	return err
+}
+
+type jsonTag Tag
+
+// MarshalJSON inlines the tag's vendor extensions
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshalExtensions(jsonTag(t), t.Extensions)
+}
+
+// UnmarshalJSON collects the tag's vendor extensions
+func (t *Tag) UnmarshalJSON(data []byte) (err error) {
+	t.Extensions, err = unmarshalExtensions(data, (*jsonTag)(t))
+	return err
+}
+
+type jsonEndpoint Endpoint
+
+// MarshalJSON inlines the endpoint's vendor extensions
+func (e *Endpoint) MarshalJSON() ([]byte, error) {
+	return marshalExtensions((*jsonEndpoint)(e), e.Extensions)
+}
+
+// UnmarshalJSON collects the endpoint's vendor extensions
+func (e *Endpoint) UnmarshalJSON(data []byte) (err error) {
+	e.Extensions, err = unmarshalExtensions(data, (*jsonEndpoint)(e))
+	return err
+}
+
+type jsonParameter Parameter
+
+// MarshalJSON inlines the parameter's vendor extensions
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return marshalExtensions(jsonParameter(p), p.Extensions)
+}
+
+// UnmarshalJSON collects the parameter's vendor extensions
+func (p *Parameter) UnmarshalJSON(data []byte) (err error) {
+	p.Extensions, err = unmarshalExtensions(data, (*jsonParameter)(p))
+	return err
+}
+
+type jsonResponse Response
+
+// MarshalJSON inlines the response's vendor extensions
+func (r Response) MarshalJSON() ([]byte, error) {
+	return marshalExtensions(jsonResponse(r), r.Extensions)
+}
+
+// UnmarshalJSON collects the response's vendor extensions
+func (r *Response) UnmarshalJSON(data []byte) (err error) {
+	r.Extensions, err = unmarshalExtensions(data, (*jsonResponse)(r))
+	return err
+}
+
+type jsonObject Object
+
+// MarshalJSON inlines the object's vendor extensions
+func (o Object) MarshalJSON() ([]byte, error) {
+	return marshalExtensions(jsonObject(o), o.Extensions)
+}
+
+// UnmarshalJSON collects the object's vendor extensions
+func (o *Object) UnmarshalJSON(data []byte) (err error) {
+	o.Extensions, err = unmarshalExtensions(data, (*jsonObject)(o))
+	return err
+}
+
+type jsonProperty Property
+
+// MarshalJSON inlines the property's vendor extensions
+func (p Property) MarshalJSON() ([]byte, error) {
+	return marshalExtensions(jsonProperty(p), p.Extensions)
+}
+
+// UnmarshalJSON collects the property's vendor extensions
+func (p *Property) UnmarshalJSON(data []byte) (err error) {
+	p.Extensions, err = unmarshalExtensions(data, (*jsonProperty)(p))
+	return err
+}
@@ -0,0 +1,115 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Extended struct {
+	Name string `json:"name" x-go-name:"FullName" x-nullable:"true"`
+}
+
+type Cached struct {
+	ID string `json:"id"`
+}
+
+func (*Cached) SwaggerExtensions() map[string]interface{} {
+	return map[string]interface{}{"x-cache": "1h"}
+}
+
+type Misnamed struct{}
+
+func (Misnamed) SwaggerExtensions() map[string]interface{} {
+	return map[string]interface{}{"cache": "1h"}
+}
+
+func TestMarshalExtensions(t *testing.T) {
+	data, err := marshalExtensions(struct{}{}, map[string]interface{}{"x-b": 1, "x-a": "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"x-a":"a","x-b":1}`, string(data))
+
+	data, err = marshalExtensions(Docs{URL: "u"}, map[string]interface{}{"x-a": true})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"description":"","url":"u","x-a":true}`, string(data))
+
+	data, err = json.Marshal(Tag{Name: "pets", Extensions: map[string]interface{}{"x-internal": true}})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"pets","description":"","x-internal":true}`, string(data))
+
+	// an api marshals the same whether passed by pointer or by value
+	api := &API{Swagger: "2.0", Extensions: map[string]interface{}{"x-logo": "logo.png"}}
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets"})
+	expected, err := json.Marshal(api)
+	assert.Nil(t, err)
+	data, err = json.Marshal(*api)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(data))
+	assert.Contains(t, string(data), `"x-logo":"logo.png"`)
+}
+
+func TestPropertyExtensionTags(t *testing.T) {
+	obj := define("", Extended{})["swaggerExtended"]
+	assert.Equal(t, map[string]interface{}{"x-go-name": "FullName", "x-nullable": true}, obj.Properties["name"].Extensions)
+
+	data, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"name":{"type":"string","x-go-name":"FullName","x-nullable":true}}}`, string(data))
+}
+
+func TestTypeExtensions(t *testing.T) {
+	obj := define("", Cached{})["swaggerCached"]
+	assert.Equal(t, map[string]interface{}{"x-cache": "1h"}, obj.Extensions)
+
+	data, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"id":{"type":"string"}},"x-cache":"1h"}`, string(data))
+
+	assert.Nil(t, define("", Extended{})["swaggerExtended"].Extensions)
+	assert.Panics(t, func() { define("", Misnamed{}) })
+}
+
+func TestExtensionsRoundTrip(t *testing.T) {
+	api := &API{
+		Info:       Info{Title: "pets", Extensions: map[string]interface{}{"x-logo": "logo.png"}},
+		Extensions: map[string]interface{}{"x-gateway": map[string]interface{}{"backend": "pets"}},
+	}
+	api.AddEndpoint(&Endpoint{
+		Method:     "GET",
+		Path:       "/pets",
+		Extensions: map[string]interface{}{"x-rate-limit": 100},
+		Parameters: []Parameter{{
+			In:         "body",
+			Name:       "body",
+			Examples:   map[string]interface{}{"application/json": "{}"},
+			Extensions: map[string]interface{}{"x-internal": true},
+		}},
+		Responses: map[string]Response{"200": {
+			Description: "ok",
+			Schema:      MakeSchema("", Extended{}),
+			Extensions:  map[string]interface{}{"x-cache": "1h"},
+		}},
+	})
+
+	data, err := json.Marshal(api)
+	assert.Nil(t, err)
+
+	loaded, err := Load(bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	e := loaded.Paths["/pets"].Get
+	assert.Equal(t, map[string]interface{}{"backend": "pets"}, loaded.Extensions["x-gateway"])
+	assert.Equal(t, "logo.png", loaded.Info.Extensions["x-logo"])
+	assert.Equal(t, float64(100), e.Extensions["x-rate-limit"])
+	assert.Equal(t, map[string]interface{}{"x-internal": true}, e.Parameters[0].Extensions)
+	assert.Equal(t, "{}", e.Parameters[0].Examples["application/json"])
+	assert.Equal(t, "1h", e.Responses["200"].Extensions["x-cache"])
+	assert.Equal(t, "FullName", loaded.Definitions["swaggerExtended"].Properties["name"].Extensions["x-go-name"])
+}
+
+func TestValidateExtension(t *testing.T) {
+	assert.NotPanics(t, func() { ValidateExtension("x-internal") })
+	assert.Panics(t, func() { ValidateExtension("internal") })
+}
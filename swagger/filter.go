@@ -0,0 +1,414 @@
+package swagger
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// vendor extensions that control the visibility of endpoints, parameters, properties and tags; see Filter
+const (
+	InternalExtension  = "x-internal"
+	AudiencesExtension = "x-audiences"
+)
+
+// vendor extensions that record the range of api versions an endpoint is available in; see the versioning package
+const (
+	SinceExtension = "x-since"
+	UntilExtension = "x-until"
+)
+
+// Visibility describes who an endpoint, parameter, property or tag is documented for
+type Visibility struct {
+	// Internal elements are hidden from every filtered view that doesn't explicitly ask for them
+	Internal bool
+
+	// Audiences the element is restricted to; empty means every audience
+	Audiences []string
+}
+
+// VisibilityOf reads the visibility recorded in the x-internal and x-audiences extensions; audiences may be a list or a
+// comma separated string
+func VisibilityOf(extensions map[string]interface{}) Visibility {
+	v := Visibility{}
+	if internal, ok := extensions[InternalExtension].(bool); ok {
+		v.Internal = internal
+	}
+
+	switch audiences := extensions[AudiencesExtension].(type) {
+	case string:
+		for _, audience := range strings.Split(audiences, ",") {
+			if audience = strings.TrimSpace(audience); audience != "" {
+				v.Audiences = append(v.Audiences, audience)
+			}
+		}
+	case []string:
+		v.Audiences = audiences
+	case []interface{}:
+		for _, audience := range audiences {
+			if s, ok := audience.(string); ok {
+				v.Audiences = append(v.Audiences, s)
+			}
+		}
+	}
+
+	return v
+}
+
+// Public keeps everything that isn't internal
+func Public(v Visibility) bool {
+	return !v.Internal
+}
+
+// ForAudience keeps everything that isn't internal and is either unrestricted or restricted to the audience
+func ForAudience(audience string) func(v Visibility) bool {
+	return func(v Visibility) bool {
+		return !v.Internal && (len(v.Audiences) == 0 || containsString(v.Audiences, audience))
+	}
+}
+
+// endpointVisibility combines the visibility of the endpoint with that of its tags; an endpoint is internal if it or
+// any of its tags is, and its own audiences take precedence over those of its tags
+func endpointVisibility(e *Endpoint, tags map[string]Visibility) Visibility {
+	v := VisibilityOf(e.Extensions)
+	own := len(v.Audiences) > 0
+	for _, name := range e.Tags {
+		tag := tags[name]
+		v.Internal = v.Internal || tag.Internal
+		if !own {
+			for _, audience := range tag.Audiences {
+				if !containsString(v.Audiences, audience) {
+					v.Audiences = append(v.Audiences, audience)
+				}
+			}
+		}
+	}
+	return v
+}
+
+// withoutVisibility returns a copy of the extensions without x-internal and x-audiences, or nil if nothing remains
+func withoutVisibility(extensions map[string]interface{}) map[string]interface{} {
+	var v map[string]interface{}
+	for name, value := range extensions {
+		if name == InternalExtension || name == AudiencesExtension {
+			continue
+		}
+		if v == nil {
+			v = map[string]interface{}{}
+		}
+		v[name] = value
+	}
+	return v
+}
+
+// Filter returns a view of the api containing only the tags, endpoints, parameters and properties for which keep
+// returns true, e.g. api.Filter(swagger.Public).  Definitions that aren't referenced by the view are removed, the
+// properties that were filtered out are removed from examples, and the x-internal and x-audiences extensions are
+// omitted from the view
+func (a *API) Filter(keep func(v Visibility) bool) *API {
+	a = a.Snapshot()
+	v := a.clone()
+	examples := exampleFilter{definitions: a.Definitions, keep: keep}
+
+	tags := map[string]Visibility{}
+	if a.Tags != nil {
+		v.Tags = []Tag{}
+		for _, tag := range a.Tags {
+			tags[tag.Name] = VisibilityOf(tag.Extensions)
+			if keep(tags[tag.Name]) {
+				tag.Extensions = withoutVisibility(tag.Extensions)
+				v.Tags = append(v.Tags, tag)
+			}
+		}
+	}
+
+	if a.Paths != nil {
+		v.Paths = map[string]*Endpoints{}
+		for rawPath, endpoints := range a.Paths {
+			filtered := endpoints.mapEndpoints(func(e *Endpoint) *Endpoint {
+				if !keep(endpointVisibility(e, tags)) {
+					return nil
+				}
+
+				c := *e
+				c.Extensions = withoutVisibility(e.Extensions)
+				if e.Parameters != nil {
+					c.Parameters = []Parameter{}
+					for _, p := range e.Parameters {
+						if keep(VisibilityOf(p.Extensions)) {
+							p.Extensions = withoutVisibility(p.Extensions)
+							p.Examples = examples.byMediaType(p.Schema, p.Examples)
+							c.Parameters = append(c.Parameters, p)
+						}
+					}
+				}
+				if e.Responses != nil {
+					c.Responses = map[string]Response{}
+					for code, r := range e.Responses {
+						if r.Schema != nil && r.Schema.Example != nil {
+							schema := *r.Schema
+							schema.Example = examples.schema(r.Schema, r.Schema.Example)
+							r.Schema = &schema
+						}
+						r.Examples = examples.byMediaType(r.Schema, r.Examples)
+						c.Responses[code] = r
+					}
+				}
+				return &c
+			})
+			if *filtered != (Endpoints{}) {
+				v.Paths[rawPath] = filtered
+			}
+		}
+	}
+
+	if a.Definitions != nil {
+		v.Definitions = map[string]Object{}
+		for name, obj := range a.Definitions {
+			v.Definitions[name] = filterObject(obj, keep)
+		}
+
+		// keep only the definitions the view references, which drops those referenced by what was filtered out as well
+		// as those that were never referenced at all
+		references := v.references()
+		for name := range v.Definitions {
+			if !references[name] {
+				delete(v.Definitions, name)
+			}
+		}
+	}
+
+	return v
+}
+
+// exampleFilter removes the properties keep doesn't allow from examples; the unfiltered definitions are used to find
+// the properties of the example's schema
+type exampleFilter struct {
+	definitions map[string]Object
+	keep        func(v Visibility) bool
+}
+
+// byMediaType filters examples keyed by media type, dropping those that end up empty
+func (f exampleFilter) byMediaType(schema *Schema, examples map[string]interface{}) map[string]interface{} {
+	if examples == nil {
+		return nil
+	}
+
+	filtered := map[string]interface{}{}
+	for mediaType, example := range examples {
+		if v := f.schema(schema, example); v != nil {
+			filtered[mediaType] = v
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+// schema filters an example of the schema.  The example is converted to its json representation first; examples that
+// can't be inspected, e.g. xml documents given as strings, are dropped by returning nil
+func (f exampleFilter) schema(schema *Schema, example interface{}) interface{} {
+	if schema == nil || example == nil || (schema.Ref == "" && schema.Items == nil) {
+		return example
+	}
+
+	v, ok := jsonValue(example)
+	if !ok {
+		return nil
+	}
+	if _, isString := v.(string); isString {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		return f.ref(schema.Ref, v)
+	}
+	return f.array(schema.Items, v)
+}
+
+func (f exampleFilter) ref(ref string, v interface{}) interface{} {
+	obj, ok := f.definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if !ok {
+		return v
+	}
+	return f.object(obj, v)
+}
+
+func (f exampleFilter) object(obj Object, v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+
+	if obj.Discriminator != "" {
+		value, _ := m[obj.Discriminator].(string)
+		for _, name := range Implementations(f.definitions, obj.Name) {
+			if impl := f.definitions[name]; impl.Extensions["x-discriminator-value"] == value {
+				obj = impl
+				break
+			}
+		}
+	}
+	obj = Flatten(obj, f.definitions)
+
+	for name, value := range m {
+		p, ok := obj.Properties[name]
+		switch {
+		case !ok:
+		case !f.keep(VisibilityOf(p.Extensions)):
+			delete(m, name)
+		default:
+			m[name] = f.property(p, value)
+		}
+	}
+	return m
+}
+
+func (f exampleFilter) property(p Property, v interface{}) interface{} {
+	switch {
+	case p.Ref != "":
+		return f.ref(p.Ref, v)
+	case p.Items != nil:
+		return f.array(p.Items, v)
+	case p.AdditionalProperties != nil:
+		if m, ok := v.(map[string]interface{}); ok {
+			for key, value := range m {
+				m[key] = f.items(p.AdditionalProperties, value)
+			}
+		}
+	}
+	return v
+}
+
+func (f exampleFilter) array(items *Items, v interface{}) interface{} {
+	if values, ok := v.([]interface{}); ok {
+		for i, value := range values {
+			values[i] = f.items(items, value)
+		}
+	}
+	return v
+}
+
+func (f exampleFilter) items(items *Items, v interface{}) interface{} {
+	if items != nil && items.Ref != "" {
+		return f.ref(items.Ref, v)
+	}
+	return v
+}
+
+// jsonValue returns the json representation of v as decoded into an interface{}
+func jsonValue(v interface{}) (interface{}, bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// filterObject returns a copy of the object, and its allOf members, with only the properties keep allows
+func filterObject(obj Object, keep func(v Visibility) bool) Object {
+	if obj.Properties != nil {
+		properties := map[string]Property{}
+		for name, p := range obj.Properties {
+			if keep(VisibilityOf(p.Extensions)) {
+				p.Extensions = withoutVisibility(p.Extensions)
+				properties[name] = p
+			}
+		}
+
+		var required []string
+		for _, name := range obj.Required {
+			if _, ok := properties[name]; ok {
+				required = append(required, name)
+			}
+		}
+
+		obj.Properties, obj.Required = properties, required
+	}
+
+	if obj.AllOf != nil {
+		members := make([]Object, 0, len(obj.AllOf))
+		for _, member := range obj.AllOf {
+			members = append(members, filterObject(member, keep))
+		}
+		obj.AllOf = members
+	}
+
+	return obj
+}
+
+// references returns the names of the definitions reachable from the api's endpoints, including the implementations
+// of reachable polymorphic definitions
+func (a *API) references() map[string]bool {
+	found := map[string]bool{}
+
+	var visit func(ref string)
+	var visitObject func(obj Object)
+	visitItems := func(items *Items) {
+		if items != nil {
+			visit(items.Ref)
+		}
+	}
+	visitSchema := func(schema *Schema) {
+		if schema != nil {
+			visit(schema.Ref)
+			visitItems(schema.Items)
+		}
+	}
+	visitObject = func(obj Object) {
+		visit(obj.Ref)
+		for _, p := range obj.Properties {
+			visit(p.Ref)
+			visitItems(p.Items)
+			visitItems(p.AdditionalProperties)
+		}
+		for _, member := range obj.AllOf {
+			visitObject(member)
+		}
+	}
+	visit = func(ref string) {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		if ref == "" || found[name] {
+			return
+		}
+		found[name] = true
+		if obj, ok := a.Definitions[name]; ok {
+			visitObject(obj)
+		}
+	}
+
+	for _, endpoints := range a.Paths {
+		endpoints.Walk(func(e *Endpoint) {
+			for _, p := range e.Parameters {
+				visitSchema(p.Schema)
+			}
+			for _, r := range e.Responses {
+				visitSchema(r.Schema)
+			}
+		})
+	}
+
+	// implementations aren't referenced by their base, only the other way around
+	for changed := true; changed; {
+		changed = false
+		for name, obj := range a.Definitions {
+			if found[name] {
+				continue
+			}
+			for _, member := range obj.AllOf {
+				if base := strings.TrimPrefix(member.Ref, "#/definitions/"); member.Ref != "" && found[base] && a.Definitions[base].Discriminator != "" {
+					visit(makeRef(name))
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,204 @@
+package swagger
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Account struct {
+	ID    string `json:"id" required:"true"`
+	Email string `json:"email"`
+	Notes string `json:"notes" required:"true" docs:"internal"`
+	Audit *Audit `json:"audit" docs:"internal"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type AdminReport struct {
+	Accounts []Account `json:"accounts"`
+}
+
+func visibilityAPI() *API {
+	api := &API{
+		Tags: []Tag{
+			{Name: "accounts"},
+			{Name: "admin", Extensions: map[string]interface{}{InternalExtension: true}},
+			{Name: "billing", Extensions: map[string]interface{}{AudiencesExtension: []string{"partners"}}},
+		},
+	}
+
+	api.AddEndpoint(&Endpoint{
+		Method: "GET",
+		Path:   "/accounts/{id}",
+		Tags:   []string{"accounts"},
+		Parameters: []Parameter{
+			{In: "path", Name: "id", Type: "string", Required: true},
+			{In: "query", Name: "debug", Type: "boolean", Extensions: map[string]interface{}{InternalExtension: true}},
+		},
+		Responses: map[string]Response{"200": {Schema: &Schema{Ref: makeRef("swaggerAccount"), Prototype: Account{}}}},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method:    "GET",
+		Path:      "/reports",
+		Tags:      []string{"admin"},
+		Responses: map[string]Response{"200": {Schema: &Schema{Ref: makeRef("swaggerAdminReport"), Prototype: AdminReport{}}}},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method:     "GET",
+		Path:       "/invoices",
+		Tags:       []string{"billing"},
+		Extensions: map[string]interface{}{"x-rate-limit": 10},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method:     "DELETE",
+		Path:       "/accounts/{id}",
+		Extensions: map[string]interface{}{AudiencesExtension: "staff, support"},
+	})
+
+	return api
+}
+
+func TestFilterPublic(t *testing.T) {
+	api := visibilityAPI()
+	v := api.Filter(Public)
+
+	assert.Equal(t, []Tag{{Name: "accounts"}, {Name: "billing"}}, v.Tags)
+	assert.NotContains(t, v.Paths, "/reports")
+	assert.NotNil(t, v.Paths["/invoices"].Get)
+	assert.Equal(t, map[string]interface{}{"x-rate-limit": 10}, v.Paths["/invoices"].Get.Extensions)
+
+	e := v.Paths["/accounts/{id}"].Get
+	assert.Len(t, e.Parameters, 1)
+	assert.Equal(t, "id", e.Parameters[0].Name)
+
+	account := v.Definitions["swaggerAccount"]
+	assert.Contains(t, account.Properties, "id")
+	assert.NotContains(t, account.Properties, "notes")
+	assert.NotContains(t, account.Properties, "audit")
+	assert.Equal(t, []string{"id"}, account.Required)
+
+	// definitions only referenced by internal endpoints and properties are removed
+	assert.NotContains(t, v.Definitions, "swaggerAdminReport")
+	assert.NotContains(t, v.Definitions, "swaggerAudit")
+
+	// the original is untouched
+	assert.Contains(t, api.Paths, "/reports")
+	assert.Len(t, api.Paths["/accounts/{id}"].Get.Parameters, 2)
+	assert.Contains(t, api.Definitions["swaggerAccount"].Properties, "notes")
+	assert.Contains(t, api.Definitions, "swaggerAudit")
+}
+
+func TestFilterForAudience(t *testing.T) {
+	api := visibilityAPI()
+
+	v := api.Filter(ForAudience("partners"))
+	assert.NotNil(t, v.Paths["/invoices"].Get)
+	assert.Nil(t, v.Paths["/accounts/{id}"].Delete)
+	assert.NotNil(t, v.Paths["/accounts/{id}"].Get)
+
+	v = api.Filter(ForAudience("support"))
+	assert.NotContains(t, v.Paths, "/invoices")
+	assert.NotNil(t, v.Paths["/accounts/{id}"].Delete)
+
+	// keeping everything returns an equivalent api
+	v = api.Filter(func(Visibility) bool { return true })
+	assert.Len(t, v.Paths, 3)
+	assert.Equal(t, len(api.Definitions), len(v.Definitions))
+}
+
+func TestFilterKeepsImplementations(t *testing.T) {
+	RegisterPolymorphic((*Payment)(nil), "method",
+		Implements("card", Card{}),
+		Implements("transfer", Transfer{}),
+	)
+
+	api := &API{}
+	api.AddEndpoint(&Endpoint{
+		Method:    "GET",
+		Path:      "/orders",
+		Responses: map[string]Response{"200": {Schema: &Schema{Ref: makeRef("swaggerOrder"), Prototype: Order{}}}},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method:     "GET",
+		Path:       "/reports",
+		Extensions: map[string]interface{}{InternalExtension: true},
+		Responses:  map[string]Response{"200": {Schema: &Schema{Ref: makeRef("swaggerAdminReport"), Prototype: AdminReport{}}}},
+	})
+
+	v := api.Filter(Public)
+	assert.Contains(t, v.Definitions, "swaggerPayment")
+	assert.Contains(t, v.Definitions, "swaggerCard")
+	assert.Contains(t, v.Definitions, "swaggerTransfer")
+	assert.NotContains(t, v.Definitions, "swaggerAdminReport")
+}
+
+func TestFilterRemovesOrphans(t *testing.T) {
+	api := visibilityAPI()
+	assert.True(t, api.RemoveEndpoint("GET", "/reports"))
+	assert.Contains(t, api.Definitions, "swaggerAdminReport")
+
+	v := api.Filter(func(Visibility) bool { return true })
+	assert.NotContains(t, v.Definitions, "swaggerAdminReport")
+	assert.Contains(t, v.Definitions, "swaggerAccount")
+}
+
+func TestFilterExamples(t *testing.T) {
+	account := Account{ID: "1", Email: "joe@example.com", Notes: "hunter2", Audit: &Audit{CreatedBy: "root"}}
+
+	api := &API{}
+	api.AddEndpoint(&Endpoint{
+		Method: "PUT",
+		Path:   "/accounts/{id}",
+		Parameters: []Parameter{{
+			In:       "body",
+			Name:     "account",
+			Schema:   &Schema{Ref: makeRef("swaggerAccount"), Prototype: Account{}},
+			Examples: map[string]interface{}{"application/json": account, "application/xml": "<account/>"},
+		}},
+		Responses: map[string]Response{
+			"200": {
+				Schema:   &Schema{Ref: makeRef("swaggerAccount"), Prototype: Account{}, Example: account},
+				Examples: map[string]interface{}{"application/json": account},
+			},
+			"207": {
+				Schema:   &Schema{Type: "array", Items: &Items{Ref: makeRef("swaggerAccount")}},
+				Examples: map[string]interface{}{"application/json": []Account{account}},
+			},
+		},
+	})
+
+	v := api.Filter(Public)
+	e := v.Paths["/accounts/{id}"].Put
+	public := map[string]interface{}{"id": "1", "email": "joe@example.com"}
+	assert.Equal(t, map[string]interface{}{"application/json": public}, e.Parameters[0].Examples)
+	assert.Equal(t, public, e.Responses["200"].Schema.Example)
+	assert.Equal(t, public, e.Responses["200"].Examples["application/json"])
+	assert.Equal(t, []interface{}{public}, e.Responses["207"].Examples["application/json"])
+
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+
+	// the original is untouched
+	assert.Equal(t, account, api.Paths["/accounts/{id}"].Put.Responses["200"].Schema.Example)
+}
+
+func TestVisibleHandler(t *testing.T) {
+	h := visibilityAPI().Handler(false, Visible(Public))
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/docs", nil))
+
+	v := struct {
+		Paths map[string]interface{} `json:"paths"`
+	}{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &v))
+	assert.Contains(t, v.Paths, "/accounts/{id}")
+	assert.NotContains(t, v.Paths, "/reports")
+	assert.NotContains(t, w.Body.String(), "x-internal")
+}
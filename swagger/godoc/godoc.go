@@ -0,0 +1,175 @@
+// Package godoc extracts type and field descriptions from go doc comments so they can be registered with
+// swagger.RegisterDescriptions, either at startup or ahead of time via go generate
+package godoc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parse extracts the doc comments of the exported types, and their fields, declared in the package found in dir.  The
+// result is keyed as expected by swagger.RegisterDescriptions
+func Parse(dir string) (map[string]string, error) {
+	importPath, err := ImportPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePackage(dir, importPath)
+}
+
+// ParsePackage is like Parse but uses the specified import path rather than deriving it from go.mod
+func ParsePackage(dir, importPath string) (map[string]string, error) {
+	fset := token.NewFileSet()
+
+	// select the files the go tool would build, skipping tests and files excluded by build constraints such as
+	// //go:build ignore generators
+	bp, err := build.ImportDir(dir, 0)
+	if _, ok := err.(*build.NoGoError); ok {
+		return nil, fmt.Errorf("no go files found in %v", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	pkg, err := doc.NewFromFiles(fset, files, importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	table := map[string]string{}
+	for _, t := range pkg.Types {
+		key := importPath + "." + t.Name
+		if text := clean(t.Doc); text != "" {
+			table[key] = text
+		}
+
+		for _, spec := range t.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != t.Name {
+				continue
+			}
+
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			for _, field := range st.Fields.List {
+				text := clean(field.Doc.Text())
+				if text == "" {
+					text = clean(field.Comment.Text())
+				}
+				if text == "" {
+					continue
+				}
+
+				for _, name := range field.Names {
+					if ast.IsExported(name.Name) {
+						table[key+"."+name.Name] = text
+					}
+				}
+			}
+		}
+	}
+
+	return table, nil
+}
+
+// clean joins the lines of each paragraph of a comment
+func clean(text string) string {
+	paragraphs := strings.Split(strings.TrimSpace(text), "\n\n")
+	for i, paragraph := range paragraphs {
+		paragraphs[i] = strings.Join(strings.Fields(paragraph), " ")
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// ImportPath derives the import path of the package in dir from the nearest go.mod
+func ImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			module := modulePath(data)
+			if module == "" {
+				return "", fmt.Errorf("no module declared in %v", filepath.Join(root, "go.mod"))
+			}
+
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("unable to find go.mod for %v", dir)
+		}
+	}
+}
+
+func modulePath(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			if v, err := strconv.Unquote(fields[1]); err == nil {
+				return v
+			}
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// Generate returns the source of a go file for package pkg that registers the descriptions when the package is
+// initialized; intended for use with go generate so the source isn't required at runtime
+func Generate(pkg string, table map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by docs-gen descriptions. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %v\n\n", pkg)
+	fmt.Fprintf(buf, "import \"github.com/threeq/docs/swagger\"\n\n")
+	fmt.Fprintf(buf, "func init() {\n\tswagger.RegisterDescriptions(map[string]string{\n")
+	for _, k := range keys {
+		fmt.Fprintf(buf, "\t\t%q: %q,\n", k, table[k])
+	}
+	fmt.Fprintf(buf, "\t})\n}\n")
+
+	return format.Source(buf.Bytes())
+}
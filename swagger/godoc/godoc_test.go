@@ -0,0 +1,60 @@
+package godoc
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	table, err := Parse("testdata/models")
+	assert.Nil(t, err)
+
+	pkg := "github.com/threeq/docs/swagger/godoc/testdata/models"
+	assert.Equal(t, map[string]string{
+		pkg + ".Pet":        "Pet is an animal available for adoption.",
+		pkg + ".Pet.ID":     "ID uniquely identifies the pet",
+		pkg + ".Pet.Name":   "Name of the pet",
+		pkg + ".Pet.Tags":   "Tags, Labels classify the pet",
+		pkg + ".Pet.Labels": "Tags, Labels classify the pet",
+		pkg + ".Owner":      "Owner adopts pets",
+		pkg + ".Status":     "Status is not a struct",
+	}, table)
+}
+
+func TestParseMissing(t *testing.T) {
+	_, err := ParsePackage("testdata/missing", "example.com/missing")
+	assert.NotNil(t, err)
+}
+
+func TestImportPath(t *testing.T) {
+	v, err := ImportPath(".")
+	assert.Nil(t, err)
+	assert.Equal(t, "github.com/threeq/docs/swagger/godoc", v)
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate("models", map[string]string{
+		"example.com/models.Pet.Name": "the \"name\"",
+		"example.com/models.Pet":      "a pet",
+	})
+	assert.Nil(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "descriptions.go", src, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, `// Code generated by docs-gen descriptions. DO NOT EDIT.
+
+package models
+
+import "github.com/threeq/docs/swagger"
+
+func init() {
+	swagger.RegisterDescriptions(map[string]string{
+		"example.com/models.Pet":      "a pet",
+		"example.com/models.Pet.Name": "the \"name\"",
+	})
+}
+`, string(src))
+}
@@ -0,0 +1,9 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+// Generator is excluded by its build constraint
+type Generator struct{}
+
+func main() {}
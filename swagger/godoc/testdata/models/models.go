@@ -0,0 +1,31 @@
+package models
+
+// Pet is an animal available
+// for adoption.
+type Pet struct {
+	// ID uniquely identifies the pet
+	ID int64 `json:"id"`
+
+	Name string `json:"name"` // Name of the pet
+
+	// Tags, Labels classify the pet
+	Tags, Labels []string
+
+	// internal is not exported
+	internal string
+
+	Owner
+}
+
+// Owner adopts pets
+type Owner struct {
+	Email string
+}
+
+type undocumented struct {
+	// Field is documented
+	Field string
+}
+
+// Status is not a struct
+type Status string
@@ -0,0 +1,529 @@
+package swagger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHandlerCacheSize is the number of host, scheme, prefix and locale variants of the document kept by Handler
+const DefaultHandlerCacheSize = 64
+
+type handlerConfig struct {
+	allowedHosts []string
+	proxies      []*net.IPNet
+	cacheSize    int
+	visible      func(v Visibility) bool
+}
+
+// HandlerOption customizes the document served by API.Handler
+type HandlerOption func(c *handlerConfig)
+
+// AllowedHosts restricts the hosts reflected in the served document; requests for any other host are served the api's
+// configured Host or, when that is empty, the first allowed host.  A host without a port matches any port
+func AllowedHosts(hosts ...string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.allowedHosts = append(c.allowedHosts, hosts...)
+	}
+}
+
+// TrustedProxies declares the addresses, as IPs or CIDRs, of proxies whose Forwarded, X-Forwarded-Proto,
+// X-Forwarded-Host and X-Forwarded-Prefix headers are honored; panics if an address can't be parsed
+func TrustedProxies(addrs ...string) HandlerOption {
+	var nets []*net.IPNet
+	for _, addr := range addrs {
+		if !strings.Contains(addr, "/") {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				panic(fmt.Errorf("TrustedProxies: invalid address, %v", addr))
+			}
+			bits := 8 * net.IPv6len
+			if v4 := ip.To4(); v4 != nil {
+				ip, bits = v4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(addr)
+		if err != nil {
+			panic(fmt.Errorf("TrustedProxies: invalid address, %v", addr))
+		}
+		nets = append(nets, n)
+	}
+
+	return func(c *handlerConfig) {
+		c.proxies = append(c.proxies, nets...)
+	}
+}
+
+// HandlerCacheSize sets the number of document variants cached by the handler; defaults to DefaultHandlerCacheSize
+func HandlerCacheSize(size int) HandlerOption {
+	return func(c *handlerConfig) {
+		c.cacheSize = size
+	}
+}
+
+// Visible serves a filtered view of the api e.g. Visible(swagger.Public) for partner facing docs; see API.Filter
+func Visible(keep func(v Visibility) bool) HandlerOption {
+	return func(c *handlerConfig) {
+		c.visible = keep
+	}
+}
+
+// document is a rendered variant of the api served by Handler
+type document struct {
+	body     []byte
+	gzipped  []byte
+	etag     string
+	gzipETag string
+}
+
+// render encodes the api as json, along with a gzipped copy, and derives strong etags for both
+func render(api *API) (*document, error) {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(api); err != nil {
+		return nil, err
+	}
+
+	gzipped := &bytes.Buffer{}
+	zw := gzip.NewWriter(gzipped)
+	zw.Write(buf.Bytes())
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	tag := hex.EncodeToString(sum[:16])
+	return &document{
+		body:     buf.Bytes(),
+		gzipped:  gzipped.Bytes(),
+		etag:     `"` + tag + `"`,
+		gzipETag: `"` + tag + `-gzip"`,
+	}, nil
+}
+
+// flight is a render of a document that concurrent requests for the same document wait for
+type flight struct {
+	done chan struct{}
+	doc  *document
+	err  error
+}
+
+// Handler is a factory method that generates an http.HandlerFunc; if enableCors is true, then the handler will generate
+// cors headers.  When translations are available, the document is localized using the lang query parameter or the
+// Accept-Language header.  The host, scheme and basePath of the document follow the request, see AllowedHosts and
+// TrustedProxies; endpoints added or removed after the handler is created are reflected in the document.
+//
+// Documents are rendered once per variant and served with ETag and Last-Modified validators, answering conditional
+// requests with 304 Not Modified, and gzip compressed when the client accepts it
+func (a *API) Handler(enableCors bool, options ...HandlerOption) http.HandlerFunc {
+	config := &handlerConfig{cacheSize: DefaultHandlerCacheSize}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	mux := &sync.Mutex{}
+	cache := newLRU(config.cacheSize)
+	var (
+		generation  uint64
+		initialized bool
+		locales     []string
+	)
+	lastModified := time.Now().UTC().Truncate(time.Second)
+
+	// load returns the cached document for the key, rendering it outside of mux on a miss; concurrent misses for the
+	// same document of the same generation share a single render
+	flights := map[string]*flight{}
+	load := func(key string, current uint64, renderFn func() (*document, error)) (*document, error) {
+		mux.Lock()
+		if current == generation {
+			if doc, ok := cache.get(key); ok {
+				mux.Unlock()
+				return doc, nil
+			}
+		}
+		id := strconv.FormatUint(current, 10) + " " + key
+		f, waiting := flights[id]
+		if !waiting {
+			f = &flight{done: make(chan struct{})}
+			flights[id] = f
+		}
+		mux.Unlock()
+
+		if waiting {
+			<-f.done
+			return f.doc, f.err
+		}
+
+		defer func() {
+			mux.Lock()
+			delete(flights, id)
+			// a newer generation may have been cached since the snapshot was taken
+			if f.err == nil && current == generation {
+				cache.add(key, f.doc)
+			}
+			mux.Unlock()
+			close(f.done)
+		}()
+		// the waiters are released with an error should the render panic
+		f.err = fmt.Errorf("rendering the document failed")
+		f.doc, f.err = renderFn()
+		return f.doc, f.err
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		api, current := a.snapshot()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		if enableCors {
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, api_key, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, PUT")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+
+		// the cached documents, locales and Last-Modified belong to a generation of the api and are refreshed when a
+		// newer one is seen; a request holding an older snapshot is served from it without touching the shared state
+		mux.Lock()
+		stale := initialized && current < generation
+		if !initialized || current > generation {
+			cache.reset()
+			generation, initialized = current, true
+			locales = api.Locales()
+
+			// Last-Modified has a resolution of one second; keep it moving forward so If-Modified-Since can't match
+			// a document rendered before the change
+			now := time.Now().UTC().Truncate(time.Second)
+			if !now.After(lastModified) {
+				now = lastModified.Add(time.Second)
+			}
+			lastModified = now
+		}
+		modified, locales := lastModified, locales
+		mux.Unlock()
+		if stale {
+			locales = api.Locales()
+		}
+
+		locale := ""
+		if len(locales) > 0 {
+			w.Header().Add("Vary", "Accept-Language")
+
+			lang := req.URL.Query().Get("lang")
+			if lang == "" {
+				lang = req.Header.Get("Accept-Language")
+			}
+			locale = negotiateLocale(lang, locales)
+			if locale != "" {
+				w.Header().Set("Content-Language", locale)
+			}
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		// customize the swagger header based on host
+		//
+		origin := config.origin(req, api.Host)
+		key := origin.host + " " + origin.scheme + " " + origin.prefix + " " + locale
+
+		doc, err := load(key, current, func() (*document, error) {
+			v := api
+			if config.visible != nil {
+				v = v.Filter(config.visible)
+			}
+			v = v.Localize(locale)
+			v.Host = origin.host
+			v.Schemes = []string{origin.scheme}
+			if origin.prefix != "" {
+				v.BasePath = path.Join(origin.prefix, "/"+v.BasePath)
+			}
+			return render(v)
+		})
+		if err != nil {
+			WriteProblem(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		body, etag := doc.body, doc.etag
+		if acceptsGzip(req.Header.Get("Accept-Encoding")) {
+			body, etag = doc.gzipped, doc.gzipETag
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+
+		if notModified(req, modified, doc.etag, doc.gzipETag) {
+			w.Header().Del("Content-Encoding")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}
+}
+
+// notModified evaluates the If-None-Match and, in its absence, If-Modified-Since conditions of the request
+func notModified(req *http.Request, modified time.Time, etags ...string) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" {
+				return true
+			}
+			for _, etag := range etags {
+				if candidate == etag {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !modified.After(t)
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows a gzip response
+func acceptsGzip(acceptEncoding string) bool {
+	gz, wildcard := -1.0, -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		switch coding {
+		case "gzip", "x-gzip":
+			gz = q
+		case "*":
+			wildcard = q
+		}
+	}
+
+	if gz >= 0 {
+		return gz > 0
+	}
+	return wildcard > 0
+}
+
+// origin is the host, scheme and path prefix the client used to reach the api
+type origin struct {
+	host   string
+	scheme string
+	prefix string
+}
+
+// origin determines how the client reached the api; forwarding headers are only honored from trusted proxies, except
+// for X-Forwarded-Proto which, for compatibility, is also honored when no proxies are configured
+func (c *handlerConfig) origin(req *http.Request, configured string) origin {
+	o := origin{host: req.Host}
+
+	if req.TLS != nil {
+		o.scheme = "https"
+	}
+
+	trusted := c.trusted(req.RemoteAddr)
+	if trusted || len(c.proxies) == 0 {
+		if v := firstValue(req.Header.Get("X-Forwarded-Proto")); validScheme(v) {
+			o.scheme = strings.ToLower(v)
+		}
+	}
+
+	if trusted {
+		if v := firstValue(req.Header.Get("X-Forwarded-Host")); v != "" {
+			o.host = v
+		}
+		if v := req.Header.Get("Forwarded"); v != "" {
+			forwarded := parseForwarded(v)
+			if validScheme(forwarded["proto"]) {
+				o.scheme = strings.ToLower(forwarded["proto"])
+			}
+			if forwarded["host"] != "" {
+				o.host = forwarded["host"]
+			}
+		}
+		o.prefix = cleanPrefix(req.Header.Get("X-Forwarded-Prefix"))
+	}
+
+	if o.scheme == "" {
+		o.scheme = req.URL.Scheme
+	}
+	if o.scheme == "" {
+		o.scheme = "http"
+	}
+
+	if len(c.allowedHosts) > 0 && !c.allowed(o.host) {
+		o.host = configured
+		if o.host == "" {
+			o.host = c.allowedHosts[0]
+		}
+	}
+
+	return o
+}
+
+func (c *handlerConfig) trusted(remoteAddr string) bool {
+	if len(c.proxies) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range c.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *handlerConfig) allowed(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+
+	for _, allowed := range c.allowedHosts {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+		if _, _, err := net.SplitHostPort(allowed); err != nil && strings.EqualFold(allowed, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstValue returns the first of the comma separated values of a header set by a chain of proxies
+func firstValue(v string) string {
+	return strings.TrimSpace(strings.Split(v, ",")[0])
+}
+
+func validScheme(scheme string) bool {
+	switch strings.ToLower(scheme) {
+	case "http", "https", "ws", "wss":
+		return true
+	}
+	return false
+}
+
+// parseForwarded returns the parameters, e.g. proto and host, of the first element of an RFC 7239 Forwarded header
+func parseForwarded(v string) map[string]string {
+	params := map[string]string{}
+	for _, pair := range strings.Split(firstValue(v), ";") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return params
+}
+
+// cleanPrefix returns the X-Forwarded-Prefix as a clean absolute path, or empty if it contains anything other than
+// path characters
+func cleanPrefix(prefix string) string {
+	prefix = firstValue(prefix)
+	if !strings.HasPrefix(prefix, "/") {
+		return ""
+	}
+
+	for _, r := range prefix {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("/-._~", r):
+		default:
+			return ""
+		}
+	}
+
+	if prefix = path.Clean(prefix); prefix == "/" {
+		return ""
+	}
+	return prefix
+}
+
+// lru is a fixed size cache of rendered documents that evicts the least recently used entry
+type lru struct {
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value *document
+}
+
+func newLRU(size int) *lru {
+	if size < 1 {
+		size = 1
+	}
+	return &lru{
+		size:  size,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (c *lru) get(key string) (*document, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+func (c *lru) add(key string, value *document) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lru) reset() {
+	c.order.Init()
+	c.items = map[string]*list.Element{}
+}
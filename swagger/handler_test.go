@@ -0,0 +1,228 @@
+package swagger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type servedDoc struct {
+	Host     string   `json:"host"`
+	Schemes  []string `json:"schemes"`
+	BasePath string   `json:"basePath"`
+}
+
+func serveDoc(t *testing.T, h http.HandlerFunc, remoteAddr, host string, headers ...string) servedDoc {
+	req := httptest.NewRequest("GET", "/docs", nil)
+	req.Host = host
+	req.RemoteAddr = remoteAddr
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	v := servedDoc{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &v))
+	return v
+}
+
+func TestHandlerHost(t *testing.T) {
+	h := (&API{BasePath: "/api"}).Handler(false)
+
+	v := serveDoc(t, h, "10.0.0.1:1234", "example.com", "X-Forwarded-Proto", "https")
+	assert.Equal(t, servedDoc{Host: "example.com", Schemes: []string{"https"}, BasePath: "/api"}, v)
+
+	// without trusted proxies, only X-Forwarded-Proto is honored
+	v = serveDoc(t, h, "10.0.0.1:1234", "example.com", "X-Forwarded-Host", "evil.com", "X-Forwarded-Prefix", "/v1")
+	assert.Equal(t, servedDoc{Host: "example.com", Schemes: []string{"http"}, BasePath: "/api"}, v)
+
+	v = serveDoc(t, h, "10.0.0.1:1234", "example.com", "X-Forwarded-Proto", "javascript")
+	assert.Equal(t, []string{"http"}, v.Schemes)
+}
+
+func TestHandlerAllowedHosts(t *testing.T) {
+	h := (&API{Host: "api.example.com"}).Handler(false, AllowedHosts("api.example.com", "localhost"))
+
+	assert.Equal(t, "localhost:8080", serveDoc(t, h, "127.0.0.1:1234", "localhost:8080").Host)
+	assert.Equal(t, "api.example.com", serveDoc(t, h, "127.0.0.1:1234", "evil.com").Host)
+
+	h = (&API{}).Handler(false, AllowedHosts("api.example.com"))
+	assert.Equal(t, "api.example.com", serveDoc(t, h, "127.0.0.1:1234", "evil.com").Host)
+}
+
+func TestHandlerTrustedProxies(t *testing.T) {
+	h := (&API{BasePath: "/api"}).Handler(false, TrustedProxies("10.0.0.0/8", "::1"))
+
+	v := serveDoc(t, h, "10.1.2.3:1234", "internal:8080",
+		"X-Forwarded-Proto", "https, http",
+		"X-Forwarded-Host", "api.example.com",
+		"X-Forwarded-Prefix", "/petstore/",
+	)
+	assert.Equal(t, servedDoc{Host: "api.example.com", Schemes: []string{"https"}, BasePath: "/petstore/api"}, v)
+
+	v = serveDoc(t, h, "[::1]:1234", "internal:8080", "Forwarded", `for=192.0.2.60;proto=https;host="api.example.com", for=10.0.0.1`)
+	assert.Equal(t, servedDoc{Host: "api.example.com", Schemes: []string{"https"}, BasePath: "/api"}, v)
+
+	// untrusted clients can't forge the forwarding headers
+	v = serveDoc(t, h, "192.0.2.1:1234", "internal:8080", "X-Forwarded-Proto", "https", "X-Forwarded-Host", "evil.com")
+	assert.Equal(t, servedDoc{Host: "internal:8080", Schemes: []string{"http"}, BasePath: "/api"}, v)
+
+	v = serveDoc(t, h, "10.1.2.3:1234", "internal:8080", "X-Forwarded-Prefix", "/<script>")
+	assert.Equal(t, "/api", v.BasePath)
+
+	assert.Panics(t, func() {
+		TrustedProxies("not an address")
+	})
+}
+
+func TestHandlerCacheIsBounded(t *testing.T) {
+	c := newLRU(2)
+	c.add("a", &document{etag: "a"})
+	c.add("b", &document{etag: "b"})
+	c.get("a")
+	c.add("c", &document{etag: "c"})
+
+	_, ok := c.get("b")
+	assert.False(t, ok, "expected the least recently used entry to be evicted")
+	_, ok = c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.order.Len())
+
+	h := (&API{}).Handler(false, HandlerCacheSize(4))
+	for i := 0; i < 100; i++ {
+		host := "host" + strconv.Itoa(i)
+		assert.Equal(t, host, serveDoc(t, h, "127.0.0.1:1234", host).Host)
+	}
+}
+
+func TestHandlerConcurrentRequests(t *testing.T) {
+	api := &API{}
+	h := api.Handler(false)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%8 == 0 {
+				api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets" + strconv.Itoa(i)})
+			}
+			host := "host" + strconv.Itoa(i%4)
+			assert.Equal(t, host, serveDoc(t, h, "127.0.0.1:1234", host).Host)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestHandlerIgnoresStaleSnapshots(t *testing.T) {
+	api := &API{}
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets"})
+	h := api.Handler(false)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/docs", nil))
+		return w
+	}
+
+	w := serve()
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+
+	// a request that took its snapshot before the latest change is served from it, leaving the shared state alone
+	state := api.shared()
+	state.mu.Lock()
+	state.generation--
+	state.mu.Unlock()
+	assert.Equal(t, lastModified, serve().Header().Get("Last-Modified"))
+
+	state.mu.Lock()
+	state.generation++
+	state.mu.Unlock()
+	w = serve()
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, lastModified, w.Header().Get("Last-Modified"))
+}
+
+func TestHandlerConditionalRequests(t *testing.T) {
+	api := &API{BasePath: "/api"}
+	h := api.Handler(false)
+
+	serve := func(headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/docs", nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w
+	}
+
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, lastModified)
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+
+	w = serve("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+
+	w = serve("If-Modified-Since", lastModified)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// a change to the api invalidates the etag
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets"})
+	w = serve("If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	w = serve("If-Modified-Since", lastModified)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandlerGzip(t *testing.T) {
+	h := (&API{BasePath: "/api"}).Handler(false)
+
+	req := httptest.NewRequest("GET", "/docs", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	r, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	v := servedDoc{}
+	assert.Nil(t, json.NewDecoder(r).Decode(&v))
+	assert.Equal(t, "/api", v.BasePath)
+
+	// the identity etag validates the gzipped representation as well
+	plain := httptest.NewRecorder()
+	h(plain, httptest.NewRequest("GET", "/docs", nil))
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+	assert.NotEqual(t, plain.Header().Get("ETag"), w.Header().Get("ETag"))
+
+	req.Header.Set("If-None-Match", plain.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	h(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.True(t, acceptsGzip("gzip"))
+	assert.True(t, acceptsGzip("deflate, *"))
+	assert.False(t, acceptsGzip(""))
+	assert.False(t, acceptsGzip("gzip;q=0, *"))
+	assert.False(t, acceptsGzip("*;q=0"))
+}
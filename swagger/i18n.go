@@ -0,0 +1,218 @@
+package swagger
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Catalog holds translated messages keyed by locale, e.g. zh or en-US, and then by message key.  Message keys are:
+//
+//	info.title, info.description
+//	tags.{name}.description
+//	{operationId}.summary, {operationId}.description, {operationId}.parameters.{name}
+//	{definition}, {definition}.{property}
+//
+// Operations may also be keyed by method and path, relative to the basePath, e.g. GET /pets/{id}.summary; this is
+// the only way to translate operations without an operationId and is consulted after the operationId
+type Catalog map[string]map[string]string
+
+// lookup returns the translation of key for the locale falling back from a regional locale, zh-CN, to its language, zh
+func (c Catalog) lookup(locale, key string) (string, bool) {
+	for _, l := range fallbacks(locale) {
+		if v, ok := c[l][key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func fallbacks(locale string) []string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		return []string{locale, locale[:i]}
+	}
+	return []string{locale}
+}
+
+func normalizeLocale(locale string) string {
+	return strings.Replace(strings.ToLower(strings.TrimSpace(locale)), "_", "-", -1)
+}
+
+// translate returns the description for the locale from the per-locale variants, e.g. desc_zh, falling back to value
+func translate(variants map[string]string, locale, value string) string {
+	for _, l := range fallbacks(locale) {
+		if v, ok := variants[l]; ok {
+			return v
+		}
+	}
+	return value
+}
+
+// Locales returns the locales for which translations are available from either the Catalog or desc_{locale} tags
+func (a *API) Locales() []string {
+	a = a.Snapshot()
+
+	found := map[string]bool{}
+	for locale := range a.Catalog {
+		found[normalizeLocale(locale)] = true
+	}
+	for _, obj := range a.Definitions {
+		members := append([]Object{obj}, obj.AllOf...)
+		for _, member := range members {
+			for _, p := range member.Properties {
+				for locale := range p.Descriptions {
+					found[locale] = true
+				}
+			}
+		}
+	}
+
+	locales := make([]string, 0, len(found))
+	for locale := range found {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Localize returns a copy of the api with descriptions translated into the specified locale; text without a
+// translation is left as is
+func (a *API) Localize(locale string) *API {
+	a = a.Snapshot()
+	v := a.clone()
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		return v
+	}
+
+	catalog := Catalog{}
+	for l, messages := range a.Catalog {
+		catalog[normalizeLocale(l)] = messages
+	}
+	text := func(key, value string) string {
+		if t, ok := catalog.lookup(locale, key); ok {
+			return t
+		}
+		return value
+	}
+
+	v.Info.Title = text("info.title", v.Info.Title)
+	v.Info.Description = text("info.description", v.Info.Description)
+
+	if a.Tags != nil {
+		v.Tags = make([]Tag, 0, len(a.Tags))
+		for _, tag := range a.Tags {
+			tag.Description = text("tags."+tag.Name+".description", tag.Description)
+			v.Tags = append(v.Tags, tag)
+		}
+	}
+
+	if a.Paths != nil {
+		v.Paths = make(map[string]*Endpoints, len(a.Paths))
+		for rawPath, endpoints := range a.Paths {
+			v.Paths[rawPath] = endpoints.mapEndpoints(func(e *Endpoint) *Endpoint {
+				c := *e
+				keys := []string{strings.ToUpper(c.Method) + " " + rawPath}
+				if c.OperationID != "" {
+					keys = append([]string{c.OperationID}, keys...)
+				}
+				operation := func(suffix, value string) string {
+					for _, key := range keys {
+						if t, ok := catalog.lookup(locale, key+suffix); ok {
+							return t
+						}
+					}
+					return value
+				}
+
+				c.Summary = operation(".summary", c.Summary)
+				c.Description = operation(".description", c.Description)
+				if c.Parameters != nil {
+					c.Parameters = make([]Parameter, 0, len(e.Parameters))
+					for _, p := range e.Parameters {
+						p.Description = operation(".parameters."+p.Name, p.Description)
+						c.Parameters = append(c.Parameters, p)
+					}
+				}
+				return &c
+			})
+		}
+	}
+
+	if a.Definitions != nil {
+		v.Definitions = make(map[string]Object, len(a.Definitions))
+		for name, obj := range a.Definitions {
+			obj.Description = text(name, obj.Description)
+			obj.Properties = localizeProperties(name, obj.Properties, locale, text)
+			if obj.AllOf != nil {
+				members := make([]Object, 0, len(obj.AllOf))
+				for _, member := range obj.AllOf {
+					member.Properties = localizeProperties(name, member.Properties, locale, text)
+					members = append(members, member)
+				}
+				obj.AllOf = members
+			}
+			v.Definitions[name] = obj
+		}
+	}
+
+	return v
+}
+
+// negotiateLocale selects the best of the available locales for an Accept-Language header; returns an empty string
+// when none of the available locales are acceptable
+func negotiateLocale(acceptLanguage string, available []string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		locale := normalizeLocale(fields[0])
+		if locale == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		for _, l := range fallbacks(c.locale) {
+			for _, locale := range available {
+				if locale == l || strings.HasPrefix(locale, l+"-") {
+					return locale
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// localizeProperties returns a copy of the properties of the named definition with translated descriptions
+func localizeProperties(name string, properties map[string]Property, locale string, text func(key, value string) string) map[string]Property {
+	if properties == nil {
+		return nil
+	}
+
+	localized := make(map[string]Property, len(properties))
+	for key, p := range properties {
+		p.Description = text(name+"."+key, translate(p.Descriptions, locale, p.Description))
+		localized[key] = p
+	}
+	return localized
+}
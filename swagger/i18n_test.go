@@ -0,0 +1,138 @@
+package swagger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Localized struct {
+	Name string `json:"name" desc:"the name" desc_zh:"名称" desc_en_GB:"the name, innit"`
+	Age  int    `json:"age" desc:"the age"`
+}
+
+func TestTagVariants(t *testing.T) {
+	field, _ := reflect.TypeOf(Localized{}).FieldByName("Name")
+	assert.Equal(t, map[string]string{"zh": "名称", "en_GB": "the name, innit"}, tagVariants(field.Tag, "desc_"))
+
+	field, _ = reflect.TypeOf(Localized{}).FieldByName("Age")
+	assert.Nil(t, tagVariants(field.Tag, "desc_"))
+
+	assert.Equal(t, map[string]string{"a": `x"y`}, tagVariants(`json:"a" desc_a:"x\"y"`, "desc_"))
+}
+
+func localizedAPI() *API {
+	api := &API{
+		Info: Info{Title: "Pets", Description: "Manages pets"},
+		Tags: []Tag{{Name: "pets", Description: "pet operations"}},
+		Catalog: Catalog{
+			"zh": {
+				"info.title":                 "宠物",
+				"tags.pets.description":      "宠物操作",
+				"getPet.summary":             "查找宠物",
+				"getPet.parameters.id":       "宠物编号",
+				"GET /pets/{id}.description": "按编号查找宠物",
+				"DELETE /pets/{id}.summary":  "删除宠物",
+				"swaggerLocalized.age":       "年龄",
+				"swaggerLocalized.absent":    "ignored",
+			},
+		},
+	}
+	api.AddEndpoint(&Endpoint{
+		Method:      "GET",
+		Path:        "/pets/{id}",
+		OperationID: "getPet",
+		Summary:     "find pet",
+		Parameters:  []Parameter{{In: "path", Name: "id", Description: "pet id"}},
+		Responses:   map[string]Response{"200": {Schema: MakeSchema("", Localized{})}},
+	})
+	api.AddEndpoint(&Endpoint{Method: "DELETE", Path: "/pets/{id}", Summary: "delete pet"})
+	return api
+}
+
+func TestLocalize(t *testing.T) {
+	api := localizedAPI()
+	assert.Equal(t, []string{"en-gb", "zh"}, api.Locales())
+
+	zh := api.Localize("zh-CN")
+	assert.Equal(t, "宠物", zh.Info.Title)
+	assert.Equal(t, "Manages pets", zh.Info.Description)
+	assert.Equal(t, "宠物操作", zh.Tags[0].Description)
+	assert.Equal(t, "查找宠物", zh.Paths["/pets/{id}"].Get.Summary)
+	assert.Equal(t, "宠物编号", zh.Paths["/pets/{id}"].Get.Parameters[0].Description)
+	assert.Equal(t, "按编号查找宠物", zh.Paths["/pets/{id}"].Get.Description)
+	assert.Equal(t, "删除宠物", zh.Paths["/pets/{id}"].Delete.Summary)
+	assert.Equal(t, "名称", zh.Definitions["swaggerLocalized"].Properties["name"].Description)
+	assert.Equal(t, "年龄", zh.Definitions["swaggerLocalized"].Properties["age"].Description)
+
+	// the original is untouched
+	assert.Equal(t, "Pets", api.Info.Title)
+	assert.Equal(t, "pet operations", api.Tags[0].Description)
+	assert.Equal(t, "find pet", api.Paths["/pets/{id}"].Get.Summary)
+	assert.Equal(t, "delete pet", api.Paths["/pets/{id}"].Delete.Summary)
+	assert.Equal(t, "pet id", api.Paths["/pets/{id}"].Get.Parameters[0].Description)
+	assert.Equal(t, "the name", api.Definitions["swaggerLocalized"].Properties["name"].Description)
+
+	gb := api.Localize("en-GB")
+	assert.Equal(t, "the name, innit", gb.Definitions["swaggerLocalized"].Properties["name"].Description)
+	assert.Equal(t, "find pet", gb.Paths["/pets/{id}"].Get.Summary)
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	available := []string{"en", "zh"}
+	assert.Equal(t, "zh", negotiateLocale("zh-CN,zh;q=0.9,en;q=0.8", available))
+	assert.Equal(t, "en", negotiateLocale("fr;q=1, en;q=0.5, zh;q=0.2", available))
+	assert.Equal(t, "", negotiateLocale("fr", available))
+	assert.Equal(t, "", negotiateLocale("zh;q=0", available))
+	assert.Equal(t, "en-gb", negotiateLocale("en", []string{"en-gb"}))
+}
+
+func TestHandlerLocale(t *testing.T) {
+	handler := localizedAPI().Handler(false)
+
+	serve := func(target, acceptLanguage string) (*httptest.ResponseRecorder, *API) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		v := &API{}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(v))
+		return w, v
+	}
+
+	w, v := serve("/swagger.json", "zh-CN,zh;q=0.9")
+	assert.Equal(t, "zh", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	assert.Equal(t, "宠物", v.Info.Title)
+
+	w, v = serve("/swagger.json?lang=fr", "zh")
+	assert.Equal(t, "", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Pets", v.Info.Title)
+}
+
+func TestHandlerLocalesFollowChanges(t *testing.T) {
+	api := &API{Info: Info{Title: "Pets"}}
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/health"})
+	handler := api.Handler(false)
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/swagger.json", nil)
+		req.Header.Set("Accept-Language", "zh")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	assert.Equal(t, "", serve().Header().Get("Content-Language"))
+
+	// the locales are computed once per version of the api, and again when it changes
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets/{id}", Responses: map[string]Response{"200": {Schema: MakeSchema("", Localized{})}}})
+	w := serve()
+	assert.Equal(t, "zh", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Body.String(), "名称")
+}
@@ -0,0 +1,51 @@
+package swagger
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Load decodes a swagger json document, e.g. as served by API.Handler, into an API.  Fields that aren't part of the
+// json document, such as the endpoint's Path and Method, are restored from the document's structure
+func Load(r io.Reader) (*API, error) {
+	api := &API{}
+	if err := json.NewDecoder(r).Decode(api); err != nil {
+		return nil, err
+	}
+
+	for rawPath, endpoints := range api.Paths {
+		for _, method := range []string{"DELETE", "HEAD", "GET", "OPTIONS", "POST", "PUT", "PATCH", "TRACE", "CONNECT"} {
+			if e := endpoints.Method(method); e != nil {
+				e.Path = rawPath
+				e.Method = method
+				restoreDeprecation(e)
+			}
+		}
+	}
+
+	for name, obj := range api.Definitions {
+		obj.Name = name
+		api.Definitions[name] = obj
+	}
+
+	return api, nil
+}
+
+// restoreDeprecation recovers the deprecation date, sunset and successor of a deprecated endpoint from its
+// x-deprecated-since, x-sunset and x-successor extensions
+func restoreDeprecation(e *Endpoint) {
+	if v, ok := e.Extensions["x-deprecated-since"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			e.DeprecatedSince = t
+		}
+	}
+	if v, ok := e.Extensions["x-sunset"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			e.Sunset = t
+		}
+	}
+	if v, ok := e.Extensions["x-successor"].(string); ok {
+		e.Successor = v
+	}
+}
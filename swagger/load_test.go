@@ -0,0 +1,48 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	api := &API{
+		Swagger:  "2.0",
+		BasePath: "/api",
+		Security: &SecurityRequirement{Requirements: []map[string][]string{{"basic": {}}}},
+	}
+	api.AddEndpoint(&Endpoint{
+		Method:      "GET",
+		Path:        "/pets/{id}",
+		OperationID: "getPet",
+		Parameters:  []Parameter{{In: "path", Name: "id", Type: "integer", Required: true}},
+		Responses:   map[string]Response{"200": {Description: "the pet", Schema: MakeSchema("", Pet{})}},
+	})
+	api.AddEndpoint(&Endpoint{
+		Method:   "POST",
+		Path:     "/login",
+		Security: &SecurityRequirement{DisableSecurity: true},
+	})
+
+	data, err := json.Marshal(api)
+	assert.Nil(t, err)
+
+	loaded, err := Load(bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	e, params := loaded.Lookup("GET", "/api/pets/1")
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "/pets/{id}", e.Path)
+		assert.Equal(t, "GET", e.Method)
+		assert.Equal(t, "getPet", e.OperationID)
+		assert.Equal(t, "1", params["id"])
+	}
+
+	assert.True(t, loaded.Paths["/login"].Post.Security.DisableSecurity)
+	assert.False(t, loaded.Security.DisableSecurity)
+	assert.Equal(t, "swaggerPet", loaded.Definitions["swaggerPet"].Name)
+	assert.Equal(t, "#/definitions/swaggerPerson", loaded.Definitions["swaggerPet"].Properties["friend"].Ref)
+}
@@ -0,0 +1,176 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Handler is an http.Handler that serves synthetic responses for every endpoint declared in a swagger.API
+type Handler struct {
+	API      *swagger.API
+	Seed     int64
+	Validate bool
+}
+
+// Option provides configuration options to the mock handler
+type Option func(h *Handler)
+
+// Seed sets the seed used to generate response bodies with swagger.ExampleSeed; the same seed always generates the same
+// body for a given endpoint and status
+func Seed(v int64) Option {
+	return func(h *Handler) {
+		h.Seed = v
+	}
+}
+
+// Validate enables or disables validation of incoming requests against the declared parameters; enabled by default
+func Validate(enabled bool) Option {
+	return func(h *Handler) {
+		h.Validate = enabled
+	}
+}
+
+// New constructs a mock handler for the api
+func New(api *swagger.API, options ...Option) *Handler {
+	h := &Handler{
+		API:      api,
+		Seed:     1,
+		Validate: true,
+	}
+
+	for _, opt := range options {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	api := h.API.Snapshot()
+	e, params := api.Lookup(req.Method, req.URL.Path)
+	if e == nil {
+		swagger.WriteProblem(w, http.StatusNotFound, "no endpoint declared for "+req.Method+" "+req.URL.Path)
+		return
+	}
+
+	if h.Validate {
+		if errs := validate(api, e, req, params); len(errs) > 0 {
+			swagger.WriteProblem(w, http.StatusBadRequest, strings.Join(errs, "; "))
+			return
+		}
+	}
+
+	code, key, applied := selectResponse(e, req.Header.Get("Prefer"))
+	if applied {
+		w.Header().Set("Preference-Applied", "code="+strconv.Itoa(code))
+	}
+
+	response, ok := e.Responses[key]
+	if !ok || (response.Schema == nil && response.Examples == nil) || code == http.StatusNoContent || req.Method == http.MethodHead {
+		w.WriteHeader(code)
+		return
+	}
+
+	contentType := "application/json"
+	if len(response.MediaTypes) > 0 {
+		contentType = response.MediaTypes[0]
+	} else if len(e.Produces) > 0 {
+		contentType = e.Produces[0]
+	}
+
+	// explicit examples take precedence over generated bodies
+	body, ok := response.Examples[contentType]
+	if !ok && response.Schema != nil {
+		body = swagger.SchemaExample(response.Schema, api.Definitions, swagger.ExampleSeed(h.Seed^hash(e.Method, e.Path, key)))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	writeBody(w, contentType, body)
+}
+
+// writeBody encodes json media types as json; other media types, e.g. text/csv or file downloads, are written as is
+func writeBody(w io.Writer, contentType string, body interface{}) {
+	if strings.Contains(contentType, "json") {
+		json.NewEncoder(w).Encode(body)
+		return
+	}
+
+	switch v := body.(type) {
+	case nil:
+	case []byte:
+		w.Write(v)
+	case string:
+		io.WriteString(w, v)
+	default:
+		fmt.Fprint(w, v)
+	}
+}
+
+func hash(values ...string) int64 {
+	h := fnv.New64a()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return int64(h.Sum64())
+}
+
+// preferredCode parses the code preference from a Prefer header e.g. Prefer: code=404
+func preferredCode(prefer string) (int, bool) {
+	for _, part := range strings.FieldsFunc(prefer, func(r rune) bool { return r == ',' || r == ';' }) {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 || strings.ToLower(kv[0]) != "code" {
+			continue
+		}
+
+		code, err := strconv.Atoi(strings.Trim(kv[1], `"`))
+		if err != nil {
+			return 0, false
+		}
+		return code, true
+	}
+
+	return 0, false
+}
+
+// selectResponse chooses the response to serve; the code requested via the Prefer header when it is declared (or
+// covered by the default response), otherwise the lowest 2xx response, otherwise the lowest declared response
+func selectResponse(e *swagger.Endpoint, prefer string) (code int, key string, applied bool) {
+	if code, ok := preferredCode(prefer); ok {
+		if _, declared := e.Responses[strconv.Itoa(code)]; declared {
+			return code, strconv.Itoa(code), true
+		}
+		if _, declared := e.Responses["default"]; declared {
+			return code, "default", true
+		}
+	}
+
+	var codes []int
+	for k := range e.Responses {
+		if v, err := strconv.Atoi(k); err == nil {
+			codes = append(codes, v)
+		}
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, strconv.Itoa(code), false
+		}
+	}
+	if len(codes) > 0 {
+		return codes[0], strconv.Itoa(codes[0]), false
+	}
+
+	return http.StatusOK, "default", false
+}
@@ -0,0 +1,174 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+)
+
+type Owner struct {
+	Name string `json:"name" required:"true"`
+}
+
+type Pet struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name" required:"true"`
+	Tags   []string `json:"tags"`
+	Owner  *Owner   `json:"owner"`
+	Status string   `json:"status"`
+}
+
+func testAPI() *swagger.API {
+	api := &swagger.API{BasePath: "/api"}
+	api.AddEndpoint(endpoint.Get("/pets/{id}", "find pet",
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Query("verbose", "boolean", "verbose output", false),
+		endpoint.Response(http.StatusOK, Pet{}, "", "the pet"),
+		endpoint.Errors(http.StatusNotFound),
+	))
+	api.AddEndpoint(endpoint.Post("/pets", "create pet",
+		endpoint.Body(Pet{}, "the pet", true),
+		endpoint.Response(http.StatusCreated, Pet{}, "", "created"),
+	))
+	api.AddEndpoint(endpoint.Delete("/pets/{id}", "delete pet",
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusNoContent, nil, "", "deleted"),
+	))
+	return api
+}
+
+func serve(h http.Handler, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestServe(t *testing.T) {
+	h := New(testAPI(), Seed(42))
+
+	w := serve(h, http.MethodGet, "/api/pets/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	pet := map[string]interface{}{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&pet))
+	assert.Contains(t, pet, "id")
+	assert.Contains(t, pet, "tags")
+	assert.IsType(t, map[string]interface{}{}, pet["owner"])
+}
+
+func TestServeDeterministic(t *testing.T) {
+	a := serve(New(testAPI(), Seed(7)), http.MethodGet, "/api/pets/1", "")
+	b := serve(New(testAPI(), Seed(7)), http.MethodGet, "/api/pets/1", "")
+	c := serve(New(testAPI(), Seed(8)), http.MethodGet, "/api/pets/1", "")
+	assert.Equal(t, a.Body.String(), b.Body.String())
+	assert.NotEqual(t, a.Body.String(), c.Body.String())
+}
+
+func TestServePrefer(t *testing.T) {
+	h := New(testAPI())
+
+	w := serve(h, http.MethodGet, "/api/pets/1", "", "Prefer", "code=404")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "code=404", w.Header().Get("Preference-Applied"))
+
+	assert.Equal(t, swagger.ProblemContentType, w.Header().Get("Content-Type"))
+	problem := swagger.ProblemDetails{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&problem))
+
+	w = serve(h, http.MethodGet, "/api/pets/1", "", "Prefer", "code=418")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Preference-Applied"))
+}
+
+func TestServeValidation(t *testing.T) {
+	h := New(testAPI())
+
+	w := serve(h, http.MethodGet, "/api/pets/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "path parameter id")
+
+	w = serve(h, http.MethodGet, "/api/pets/1?verbose=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(h, http.MethodPost, "/api/pets", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "body is required")
+
+	w = serve(h, http.MethodPost, "/api/pets", "{", "Content-Type", "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(h, http.MethodPost, "/api/pets", `{"name":"fido"}`, "Content-Type", "application/json")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// the body is checked against its schema
+	w = serve(h, http.MethodPost, "/api/pets", `{"id":1.5,"tags":["a",2],"owner":{}}`, "Content-Type", "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "body.name is required")
+	assert.Contains(t, w.Body.String(), "body.id: expected integer, got number")
+	assert.Contains(t, w.Body.String(), "body.tags[1]: expected string, got number")
+	assert.Contains(t, w.Body.String(), "body.owner.name is required")
+
+	w = serve(h, http.MethodPost, "/api/pets", `[]`, "Content-Type", "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "body: expected object, got array")
+
+	w = serve(New(testAPI(), Validate(false)), http.MethodGet, "/api/pets/abc", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServeNoContent(t *testing.T) {
+	h := New(testAPI())
+
+	w := serve(h, http.MethodDelete, "/api/pets/1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = serve(h, http.MethodPut, "/api/pets/1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServeExample(t *testing.T) {
+	api := &swagger.API{}
+	api.AddEndpoint(endpoint.Get("/pets/{id}", "find pet",
+		endpoint.Response(http.StatusOK, Pet{}, "", "the pet",
+			endpoint.Example("application/json", map[string]interface{}{"name": "fido"}),
+		),
+	))
+
+	w := serve(New(api), http.MethodGet, "/pets/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"fido"}`, w.Body.String())
+}
+
+func TestServeMediaTypes(t *testing.T) {
+	api := &swagger.API{}
+	api.AddEndpoint(endpoint.Get("/pets/export", "export pets",
+		endpoint.Response(http.StatusOK, "", "", "pets as csv",
+			endpoint.MediaTypes("text/csv"),
+			endpoint.Example("text/csv", "id,name\n1,fido\n"),
+		),
+	))
+	api.AddEndpoint(endpoint.Get("/pets/{id}/photo", "download photo",
+		endpoint.File(http.StatusOK, "the photo", "image/png"),
+	))
+
+	w := serve(New(api), http.MethodGet, "/pets/export", "")
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name\n1,fido\n", w.Body.String())
+
+	w = serve(New(api), http.MethodGet, "/pets/1/photo", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Body.String())
+}
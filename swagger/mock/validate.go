@@ -0,0 +1,259 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// validate checks the request against the endpoint's declared parameters and returns a description of each violation
+func validate(api *swagger.API, e *swagger.Endpoint, req *http.Request, pathParams map[string]string) []string {
+	var errs []string
+
+	for _, p := range e.Parameters {
+		var (
+			value   string
+			present bool
+		)
+
+		switch p.In {
+		case "path":
+			value, present = pathParams[p.Name]
+		case "query":
+			values, ok := req.URL.Query()[p.Name]
+			present = ok
+			if ok && len(values) > 0 {
+				value = values[0]
+			}
+		case "header":
+			value = req.Header.Get(p.Name)
+			present = value != ""
+		case "body":
+			errs = append(errs, validateBody(api, p, req)...)
+			continue
+		default:
+			continue
+		}
+
+		if !present {
+			if p.Required {
+				errs = append(errs, fmt.Sprintf("%v parameter %v is required", p.In, p.Name))
+			}
+			continue
+		}
+
+		if err := validateType(p.Type, value); err != nil {
+			errs = append(errs, fmt.Sprintf("%v parameter %v: %v", p.In, p.Name, err))
+		}
+	}
+
+	return errs
+}
+
+func validateType(typ, value string) error {
+	var err error
+	switch typ {
+	case "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("expected %v, got %q", typ, value)
+	}
+	return nil
+}
+
+// validateBody checks that a json body conforms to the parameter's schema: required properties must be present and
+// values must have the declared types and enums
+func validateBody(api *swagger.API, p swagger.Parameter, req *http.Request) []string {
+	if req.Body == nil {
+		if p.Required {
+			return []string{"body is required"}
+		}
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to read body: %v", err)}
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if p.Required {
+			return []string{"body is required"}
+		}
+		return nil
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return []string{fmt.Sprintf("body is not valid json: %v", err)}
+	}
+
+	c := &bodyChecker{definitions: api.Definitions}
+	c.schema("body", p.Schema, v)
+	return c.errs
+}
+
+// bodyChecker checks a decoded json body against its schema, applying the type rules of the other parameters
+type bodyChecker struct {
+	definitions map[string]swagger.Object
+	errs        []string
+}
+
+func (c *bodyChecker) errorf(path, format string, args ...interface{}) {
+	c.errs = append(c.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *bodyChecker) schema(path string, schema *swagger.Schema, v interface{}) {
+	switch {
+	case schema == nil:
+	case schema.Ref != "":
+		c.ref(path, schema.Ref, v)
+	case schema.Type == "array":
+		c.array(path, schema.Items, v)
+	default:
+		c.value(path, schema.Type, nil, v)
+	}
+}
+
+func (c *bodyChecker) ref(path, ref string, v interface{}) {
+	obj, ok := c.definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if !ok || v == nil {
+		return
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		c.errorf(path, "expected object, got %v", kind(v))
+		return
+	}
+
+	if obj.Discriminator != "" {
+		value, _ := m[obj.Discriminator].(string)
+		for _, name := range swagger.Implementations(c.definitions, obj.Name) {
+			if impl := c.definitions[name]; impl.Extensions["x-discriminator-value"] == value {
+				obj = impl
+				break
+			}
+		}
+	}
+	obj = swagger.Flatten(obj, c.definitions)
+
+	for _, name := range obj.Required {
+		if m[name] == nil {
+			c.errs = append(c.errs, fmt.Sprintf("%v.%v is required", path, name))
+		}
+	}
+	for name, value := range m {
+		p, ok := obj.Properties[name]
+		switch {
+		case !ok, value == nil:
+		case p.Ref != "":
+			c.ref(path+"."+name, p.Ref, value)
+		case p.Type == "array":
+			c.array(path+"."+name, p.Items, value)
+		case p.AdditionalProperties != nil:
+			if values, ok := value.(map[string]interface{}); ok {
+				for key, value := range values {
+					c.items(path+"."+name+"."+key, p.AdditionalProperties, value)
+				}
+			}
+		default:
+			c.value(path+"."+name, p.Type, p.Enum, value)
+		}
+	}
+}
+
+func (c *bodyChecker) array(path string, items *swagger.Items, v interface{}) {
+	if v == nil {
+		return
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		c.errorf(path, "expected array, got %v", kind(v))
+		return
+	}
+	for i, value := range values {
+		c.items(path+"["+strconv.Itoa(i)+"]", items, value)
+	}
+}
+
+func (c *bodyChecker) items(path string, items *swagger.Items, v interface{}) {
+	switch {
+	case items == nil, v == nil:
+	case items.Ref != "":
+		c.ref(path, items.Ref, v)
+	default:
+		c.value(path, items.Type, nil, v)
+	}
+}
+
+// value checks a primitive value; numbers are checked with validateType so they follow the rules of parameters
+func (c *bodyChecker) value(path, typ string, enum []string, v interface{}) {
+	if v == nil {
+		return
+	}
+
+	ok := true
+	switch typ {
+	case "integer", "number":
+		n, isNumber := v.(json.Number)
+		ok = isNumber && validateType(typ, n.String()) == nil
+	case "boolean":
+		_, ok = v.(bool)
+	case "string":
+		_, ok = v.(string)
+	case "object":
+		_, ok = v.(map[string]interface{})
+	}
+	if !ok {
+		c.errorf(path, "expected %v, got %v", typ, kind(v))
+		return
+	}
+
+	if len(enum) > 0 {
+		for _, value := range enum {
+			if value == fmt.Sprint(v) {
+				return
+			}
+		}
+		c.errorf(path, "expected one of %v, got %v", strings.Join(enum, ", "), v)
+	}
+}
+
+// kind describes the json type of v
+func kind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
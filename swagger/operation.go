@@ -0,0 +1,48 @@
+package swagger
+
+import "sort"
+
+// operationMethods lists the http methods in the order Operations returns the endpoints of a path
+var operationMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+
+// Operation is an endpoint along with the method and path it's served at; the path is relative to the basePath
+type Operation struct {
+	Method   string
+	Path     string
+	Endpoint *Endpoint
+}
+
+// Operations returns the api's endpoints sorted by path and then by method in the order GET, HEAD, POST, PUT, PATCH,
+// DELETE, OPTIONS, TRACE, CONNECT, so that output generated from them is stable
+func (a *API) Operations() []Operation {
+	a = a.Snapshot()
+
+	paths := make([]string, 0, len(a.Paths))
+	for rawPath := range a.Paths {
+		paths = append(paths, rawPath)
+	}
+	sort.Strings(paths)
+
+	var ops []Operation
+	for _, rawPath := range paths {
+		for _, method := range operationMethods {
+			if e := a.Paths[rawPath].Method(method); e != nil {
+				ops = append(ops, Operation{Method: method, Path: rawPath, Endpoint: e})
+			}
+		}
+	}
+	return ops
+}
+
+// EffectiveSecurity returns the security requirement that applies to the endpoint: the endpoint's own, none when the
+// endpoint uses NoSecurity, or else the api's default.  Returns nil when requests to the endpoint aren't authenticated
+func (a *API) EffectiveSecurity(e *Endpoint) *SecurityRequirement {
+	requirement := e.Security
+	if requirement == nil {
+		requirement = a.Security
+	}
+	if requirement == nil || requirement.DisableSecurity || len(requirement.Requirements) == 0 {
+		return nil
+	}
+	return requirement
+}
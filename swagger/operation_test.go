@@ -0,0 +1,43 @@
+package swagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperations(t *testing.T) {
+	api := &API{BasePath: "/api"}
+	api.AddEndpoint(&Endpoint{Method: "DELETE", Path: "/pets/{id}"})
+	api.AddEndpoint(&Endpoint{Method: "POST", Path: "/pets"})
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets/{id}"})
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets"})
+
+	var names []string
+	for _, op := range api.Operations() {
+		names = append(names, op.Method+" "+op.Path)
+		assert.Equal(t, op.Method, op.Endpoint.Method)
+	}
+	assert.Equal(t, []string{"GET /pets", "POST /pets", "GET /pets/{id}", "DELETE /pets/{id}"}, names)
+}
+
+func TestEffectiveSecurity(t *testing.T) {
+	basic := &SecurityRequirement{Requirements: []map[string][]string{{"basic": {}}}}
+	oauth := &SecurityRequirement{Requirements: []map[string][]string{{"oauth": {"read"}}}}
+	api := &API{Security: basic}
+
+	assert.Equal(t, basic, api.EffectiveSecurity(&Endpoint{}))
+	assert.Equal(t, oauth, api.EffectiveSecurity(&Endpoint{Security: oauth}))
+	assert.Nil(t, api.EffectiveSecurity(&Endpoint{Security: &SecurityRequirement{DisableSecurity: true}}))
+	assert.Nil(t, (&API{}).EffectiveSecurity(&Endpoint{}))
+	assert.Nil(t, (&API{Security: &SecurityRequirement{}}).EffectiveSecurity(&Endpoint{}))
+}
+
+func TestSecuritySchemeKind(t *testing.T) {
+	assert.Equal(t, "basic", SecurityScheme{Type: "basic"}.Kind())
+	assert.Equal(t, "basic", SecurityScheme{Type: "http", Scheme: "Basic"}.Kind())
+	assert.Equal(t, "bearer", SecurityScheme{Type: "http", Scheme: "bearer"}.Kind())
+	assert.Equal(t, "bearer", SecurityScheme{Type: "openIdConnect"}.Kind())
+	assert.Equal(t, "apiKey", SecurityScheme{Type: "apiKey"}.Kind())
+	assert.Equal(t, "oauth2", SecurityScheme{Type: "oauth2"}.Kind())
+}
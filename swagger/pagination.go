@@ -0,0 +1,182 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationStyle identifies how a list endpoint is paged
+type PaginationStyle string
+
+const (
+	// OffsetPagination pages with the offset and limit query parameters
+	OffsetPagination PaginationStyle = "offset"
+	// PagePagination pages with the page, starting at 1, and page_size query parameters
+	PagePagination PaginationStyle = "page"
+	// CursorPagination pages with the opaque cursor and limit query parameters
+	CursorPagination PaginationStyle = "cursor"
+)
+
+// query parameters and response headers used by the pagination styles
+const (
+	OffsetParam   = "offset"
+	LimitParam    = "limit"
+	PageParam     = "page"
+	PageSizeParam = "page_size"
+	CursorParam   = "cursor"
+
+	TotalCountHeader = "X-Total-Count"
+	LinkHeader       = "Link"
+)
+
+var (
+	// DefaultPageSize is the limit or page size used when the request doesn't specify one
+	DefaultPageSize = 20
+	// MaxPageSize caps the limit or page size requested by clients
+	MaxPageSize = 100
+)
+
+// Pagination holds the pagination parameters of a request; only the fields relevant to Style are set
+type Pagination struct {
+	Style  PaginationStyle
+	Offset int
+	Limit  int
+	Page   int
+	Cursor string
+}
+
+// ParsePagination reads the pagination query parameters for style from the request.  Missing parameters take their
+// defaults and sizes above MaxPageSize are capped; malformed or negative values return an error suitable for a 400
+func ParsePagination(req *http.Request, style PaginationStyle) (Pagination, error) {
+	query := req.URL.Query()
+	p := Pagination{Style: style}
+
+	size := LimitParam
+	switch style {
+	case OffsetPagination:
+		offset, err := queryInt(query, OffsetParam, 0)
+		if err != nil {
+			return p, err
+		}
+		p.Offset = offset
+
+	case PagePagination:
+		size = PageSizeParam
+		page, err := queryInt(query, PageParam, 1)
+		if err != nil {
+			return p, err
+		}
+		if page < 1 {
+			return p, fmt.Errorf("%v must be at least 1", PageParam)
+		}
+		p.Page = page
+
+	case CursorPagination:
+		p.Cursor = query.Get(CursorParam)
+
+	default:
+		return p, fmt.Errorf("unknown pagination style, %v", style)
+	}
+
+	limit, err := queryInt(query, size, DefaultPageSize)
+	if err != nil {
+		return p, err
+	}
+	if limit < 1 {
+		return p, fmt.Errorf("%v must be at least 1", size)
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	p.Limit = limit
+
+	if style == PagePagination {
+		p.Offset = (p.Page - 1) * p.Limit
+	}
+
+	return p, nil
+}
+
+func queryInt(query url.Values, name string, defaultValue int) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%v must be a non-negative integer", name)
+	}
+	return i, nil
+}
+
+// SetHeaders sets the X-Total-Count and Link headers for the page of results requested by req.  total is the number
+// of items across all pages, or negative when unknown, and next is the cursor of the following page for
+// CursorPagination, empty on the last page.  The Link header is added to, so links set earlier e.g. by
+// SetDeprecationHeaders are kept; a Limit of zero or less is treated as DefaultPageSize
+func (p Pagination) SetHeaders(w http.ResponseWriter, req *http.Request, total int, next string) {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageSize
+	}
+
+	var links []string
+	link := func(rel string, params map[string]string) {
+		u := *req.URL
+		query := u.Query()
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%v>; rel="%v"`, u.String(), rel))
+	}
+
+	if total >= 0 && p.Style != CursorPagination {
+		w.Header().Set(TotalCountHeader, strconv.Itoa(total))
+	}
+
+	switch p.Style {
+	case OffsetPagination:
+		limit := strconv.Itoa(p.Limit)
+		link("first", map[string]string{OffsetParam: "0", LimitParam: limit})
+		if p.Offset > 0 {
+			prev := p.Offset - p.Limit
+			if prev < 0 {
+				prev = 0
+			}
+			link("prev", map[string]string{OffsetParam: strconv.Itoa(prev), LimitParam: limit})
+		}
+		if total < 0 || p.Offset+p.Limit < total {
+			link("next", map[string]string{OffsetParam: strconv.Itoa(p.Offset + p.Limit), LimitParam: limit})
+		}
+		if total > 0 {
+			last := (total - 1) / p.Limit * p.Limit
+			link("last", map[string]string{OffsetParam: strconv.Itoa(last), LimitParam: limit})
+		}
+
+	case PagePagination:
+		size := strconv.Itoa(p.Limit)
+		link("first", map[string]string{PageParam: "1", PageSizeParam: size})
+		if p.Page > 1 {
+			link("prev", map[string]string{PageParam: strconv.Itoa(p.Page - 1), PageSizeParam: size})
+		}
+		if total < 0 || p.Page*p.Limit < total {
+			link("next", map[string]string{PageParam: strconv.Itoa(p.Page + 1), PageSizeParam: size})
+		}
+		if total > 0 {
+			last := (total + p.Limit - 1) / p.Limit
+			link("last", map[string]string{PageParam: strconv.Itoa(last), PageSizeParam: size})
+		}
+
+	case CursorPagination:
+		if next != "" {
+			link("next", map[string]string{CursorParam: next, LimitParam: strconv.Itoa(p.Limit)})
+		}
+	}
+
+	if len(links) > 0 {
+		w.Header().Add(LinkHeader, strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,70 @@
+package swagger
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePagination(t *testing.T) {
+	p, err := ParsePagination(httptest.NewRequest("GET", "/pets?offset=40&limit=500", nil), OffsetPagination)
+	assert.Nil(t, err)
+	assert.Equal(t, Pagination{Style: OffsetPagination, Offset: 40, Limit: MaxPageSize}, p)
+
+	p, err = ParsePagination(httptest.NewRequest("GET", "/pets?page=3&page_size=10", nil), PagePagination)
+	assert.Nil(t, err)
+	assert.Equal(t, Pagination{Style: PagePagination, Page: 3, Offset: 20, Limit: 10}, p)
+
+	p, err = ParsePagination(httptest.NewRequest("GET", "/pets?cursor=abc", nil), CursorPagination)
+	assert.Nil(t, err)
+	assert.Equal(t, Pagination{Style: CursorPagination, Cursor: "abc", Limit: DefaultPageSize}, p)
+
+	_, err = ParsePagination(httptest.NewRequest("GET", "/pets?offset=-1", nil), OffsetPagination)
+	assert.NotNil(t, err)
+	_, err = ParsePagination(httptest.NewRequest("GET", "/pets?page=0", nil), PagePagination)
+	assert.NotNil(t, err)
+	_, err = ParsePagination(httptest.NewRequest("GET", "/pets?limit=x", nil), CursorPagination)
+	assert.NotNil(t, err)
+}
+
+func TestPaginationSetHeaders(t *testing.T) {
+	req := httptest.NewRequest("GET", "/pets?page=2&page_size=10&status=sold", nil)
+	p, _ := ParsePagination(req, PagePagination)
+
+	w := httptest.NewRecorder()
+	p.SetHeaders(w, req, 25, "")
+	assert.Equal(t, "25", w.Header().Get(TotalCountHeader))
+	assert.Equal(t, `</pets?page=1&page_size=10&status=sold>; rel="first", `+
+		`</pets?page=1&page_size=10&status=sold>; rel="prev", `+
+		`</pets?page=3&page_size=10&status=sold>; rel="next", `+
+		`</pets?page=3&page_size=10&status=sold>; rel="last"`, w.Header().Get(LinkHeader))
+
+	req = httptest.NewRequest("GET", "/pets?offset=20&limit=10", nil)
+	p, _ = ParsePagination(req, OffsetPagination)
+	w = httptest.NewRecorder()
+	p.SetHeaders(w, req, 25, "")
+	assert.Equal(t, `</pets?limit=10&offset=0>; rel="first", `+
+		`</pets?limit=10&offset=10>; rel="prev", `+
+		`</pets?limit=10&offset=20>; rel="last"`, w.Header().Get(LinkHeader))
+
+	req = httptest.NewRequest("GET", "/pets", nil)
+	p, _ = ParsePagination(req, CursorPagination)
+	w = httptest.NewRecorder()
+	p.SetHeaders(w, req, 100, "next-page")
+	assert.Empty(t, w.Header().Get(TotalCountHeader))
+	assert.Equal(t, `</pets?cursor=next-page&limit=20>; rel="next"`, w.Header().Get(LinkHeader))
+}
+
+func TestPaginationKeepsLinks(t *testing.T) {
+	req := httptest.NewRequest("GET", "/pets", nil)
+	w := httptest.NewRecorder()
+	SetDeprecationHeaders(w.Header(), &Endpoint{Deprecated: true, Successor: "/v2/pets"})
+
+	// a hand built Pagination without a limit uses the default page size
+	Pagination{Style: OffsetPagination}.SetHeaders(w, req, 45, "")
+	assert.Equal(t, []string{
+		`</v2/pets>; rel="successor-version"`,
+		`</pets?limit=20&offset=0>; rel="first", </pets?limit=20&offset=20>; rel="next", </pets?limit=20&offset=40>; rel="last"`,
+	}, w.Header()[LinkHeader])
+}
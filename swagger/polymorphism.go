@@ -0,0 +1,222 @@
+package swagger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Implementation associates a discriminator value with a concrete type that implements a polymorphic interface
+type Implementation struct {
+	Value     string
+	Prototype interface{}
+}
+
+// Implements returns an Implementation for use with RegisterPolymorphic
+func Implements(value string, prototype interface{}) Implementation {
+	return Implementation{
+		Value:     value,
+		Prototype: prototype,
+	}
+}
+
+type polymorphicType struct {
+	discriminator   string
+	implementations []Implementation
+}
+
+var polymorphics = struct {
+	sync.RWMutex
+	types map[reflect.Type]polymorphicType
+}{
+	types: map[reflect.Type]polymorphicType{},
+}
+
+// RegisterPolymorphic registers the concrete implementations of an interface, passed as a nil pointer e.g.
+// (*Event)(nil), along with the name of the property that discriminates between them.  Struct fields, slices and
+// maps of the interface, as well as the interface used as a body or response prototype, are then documented as a base
+// definition carrying the discriminator and one allOf definition per implementation
+func RegisterPolymorphic(iface interface{}, discriminator string, implementations ...Implementation) {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Errorf("RegisterPolymorphic requires a nil pointer to an interface e.g. (*Event)(nil); got %v", t))
+	}
+	if discriminator == "" {
+		panic(fmt.Errorf("RegisterPolymorphic requires a discriminator for %v", t.Elem()))
+	}
+
+	for _, impl := range implementations {
+		pt := reflect.TypeOf(impl.Prototype)
+		if pt == nil || !pt.Implements(t.Elem()) && !reflect.PtrTo(pt).Implements(t.Elem()) {
+			panic(fmt.Errorf("RegisterPolymorphic: %v does not implement %v", pt, t.Elem()))
+		}
+	}
+
+	polymorphics.Lock()
+	defer polymorphics.Unlock()
+
+	polymorphics.types[t.Elem()] = polymorphicType{
+		discriminator:   discriminator,
+		implementations: implementations,
+	}
+}
+
+func lookupPolymorphic(t reflect.Type) (polymorphicType, bool) {
+	polymorphics.RLock()
+	defer polymorphics.RUnlock()
+
+	v, ok := polymorphics.types[t]
+	return v, ok
+}
+
+// polymorphicBase returns the registered interface t either is or implements, or nil
+func polymorphicBase(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	polymorphics.RLock()
+	defer polymorphics.RUnlock()
+
+	for iface, poly := range polymorphics.types {
+		if iface == t {
+			return iface
+		}
+		for _, impl := range poly.implementations {
+			pt := reflect.TypeOf(impl.Prototype)
+			if pt.Kind() == reflect.Ptr {
+				pt = pt.Elem()
+			}
+			if pt == t {
+				return iface
+			}
+		}
+	}
+
+	return nil
+}
+
+// definePolymorphic adds the base definition and the allOf definitions of each implementation of a registered
+// interface to objMap; returns true if anything was added
+func definePolymorphic(t reflect.Type, objMap map[string]Object) bool {
+	if t == nil {
+		return false
+	}
+
+	poly, ok := lookupPolymorphic(t)
+	if !ok {
+		return false
+	}
+
+	base := makeName(t)
+	if _, exists := objMap[base]; exists {
+		return false
+	}
+
+	objMap[base] = Object{
+		GoType:        t,
+		Type:          "object",
+		Name:          base,
+		Description:   typeDescription(t),
+		Discriminator: poly.discriminator,
+		Required:      []string{poly.discriminator},
+		Properties: map[string]Property{
+			poly.discriminator: {GoType: reflect.TypeOf(""), Type: "string"},
+		},
+	}
+
+	for _, impl := range poly.implementations {
+		child := defineObject("", impl.Prototype)
+		delete(child.Properties, poly.discriminator)
+
+		var required []string
+		for _, name := range child.Required {
+			if name != poly.discriminator {
+				required = append(required, name)
+			}
+		}
+
+		objMap[child.Name] = Object{
+			GoType:      child.GoType,
+			Name:        child.Name,
+			Description: child.Description,
+			AllOf: []Object{
+				{Ref: makeRef(base)},
+				{Type: "object", Required: required, Properties: child.Properties},
+			},
+			Extensions: map[string]interface{}{
+				"x-discriminator-value": impl.Value,
+			},
+		}
+	}
+
+	return true
+}
+
+// Flatten merges the members of an allOf object, resolving references against definitions, into a single object.
+// When the object is an implementation of a polymorphic definition, the discriminator property is restricted to the
+// implementation's x-discriminator-value.  Objects without allOf are returned unchanged
+func Flatten(obj Object, definitions map[string]Object) Object {
+	if len(obj.AllOf) == 0 {
+		return obj
+	}
+
+	flat := Object{
+		IsArray:     obj.IsArray,
+		GoType:      obj.GoType,
+		Name:        obj.Name,
+		Type:        "object",
+		Description: obj.Description,
+		Properties:  map[string]Property{},
+		Extensions:  obj.Extensions,
+	}
+
+	var discriminator string
+	required := map[string]bool{}
+	for _, member := range obj.AllOf {
+		if member.Ref != "" {
+			member = Flatten(definitions[strings.TrimPrefix(member.Ref, "#/definitions/")], definitions)
+		}
+		if member.Discriminator != "" {
+			discriminator = member.Discriminator
+		}
+		for name, p := range member.Properties {
+			flat.Properties[name] = p
+		}
+		for _, name := range member.Required {
+			required[name] = true
+		}
+	}
+
+	if value, ok := obj.Extensions["x-discriminator-value"].(string); ok && discriminator != "" {
+		p := flat.Properties[discriminator]
+		p.Enum = []string{value}
+		flat.Properties[discriminator] = p
+	}
+
+	for name := range required {
+		flat.Required = append(flat.Required, name)
+	}
+	sort.Strings(flat.Required)
+
+	return flat
+}
+
+// Implementations returns the sorted names of the definitions composed, via allOf, from the named base definition
+func Implementations(definitions map[string]Object, base string) []string {
+	ref := makeRef(base)
+
+	var names []string
+	for name, obj := range definitions {
+		for _, member := range obj.AllOf {
+			if member.Ref == ref {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
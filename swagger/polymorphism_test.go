@@ -0,0 +1,115 @@
+package swagger
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Payment interface {
+	payment()
+}
+
+type Card struct {
+	Method string `json:"method" required:"true"`
+	Number string `json:"number" required:"true"`
+}
+
+func (Card) payment() {}
+
+type Transfer struct {
+	IBAN   string  `json:"iban"`
+	Holder *Person `json:"holder"`
+}
+
+func (*Transfer) payment() {}
+
+type Order struct {
+	Payment  Payment     `json:"payment"`
+	Payments []Payment   `json:"payments"`
+	Extra    interface{} `json:"extra"`
+}
+
+func init() {
+	RegisterPolymorphic((*Payment)(nil), "method",
+		Implements("card", Card{}),
+		Implements("transfer", &Transfer{}),
+	)
+}
+
+func TestPolymorphicDefinitions(t *testing.T) {
+	v := define("", Order{})
+
+	order := v["swaggerOrder"]
+	assert.Equal(t, "#/definitions/swaggerPayment", order.Properties["payment"].Ref)
+	assert.Equal(t, &Items{Ref: "#/definitions/swaggerPayment"}, order.Properties["payments"].Items)
+	assert.Equal(t, "object", order.Properties["extra"].Type)
+
+	base := v["swaggerPayment"]
+	assert.Equal(t, "method", base.Discriminator)
+	assert.Equal(t, []string{"method"}, base.Required)
+
+	card := v["swaggerCard"]
+	assert.Equal(t, "", card.Type)
+	assert.Equal(t, "card", card.Extensions["x-discriminator-value"])
+	assert.Len(t, card.AllOf, 2)
+	assert.Equal(t, "#/definitions/swaggerPayment", card.AllOf[0].Ref)
+	assert.Equal(t, []string{"number"}, card.AllOf[1].Required)
+	assert.NotContains(t, card.AllOf[1].Properties, "method")
+
+	assert.Contains(t, v, "swaggerTransfer")
+	assert.Contains(t, v, "swaggerPerson", "expected definitions referenced by implementations")
+
+	assert.Equal(t, []string{"swaggerCard", "swaggerTransfer"}, Implementations(v, "swaggerPayment"))
+}
+
+func TestPolymorphicPrototype(t *testing.T) {
+	// interfaces and implementations used directly as prototypes resolve to the same definitions
+	schema := MakeSchema("", reflect.TypeOf((*Payment)(nil)))
+	assert.Equal(t, "#/definitions/swaggerPayment", schema.Ref)
+
+	v := define("", reflect.TypeOf((*Payment)(nil)))
+	assert.Contains(t, v, "swaggerPayment")
+	assert.Contains(t, v, "swaggerCard")
+
+	v = define("", Card{})
+	assert.Len(t, v["swaggerCard"].AllOf, 2)
+
+	data, err := json.Marshal(v["swaggerCard"])
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"allOf": [
+			{"$ref": "#/definitions/swaggerPayment"},
+			{"type": "object", "required": ["number"], "properties": {"number": {"type": "string"}}}
+		],
+		"x-discriminator-value": "card"
+	}`, string(data))
+}
+
+func TestFlatten(t *testing.T) {
+	v := define("", Order{})
+
+	card := Flatten(v["swaggerCard"], v)
+	assert.Equal(t, "object", card.Type)
+	assert.Equal(t, []string{"method", "number"}, card.Required)
+	assert.Equal(t, []string{"card"}, card.Properties["method"].Enum)
+
+	assert.Equal(t, map[string]interface{}{"method": "card", "number": "string"}, Example(v["swaggerPayment"], v))
+}
+
+func TestInterfaceWithoutValue(t *testing.T) {
+	// previously panicked when the prototype was a reflect.Type or the interface was nil
+	v := define("", reflect.TypeOf(APIResponse{}))
+	assert.Equal(t, "object", v["swaggerAPIResponse"].Properties["data"].Type)
+
+	v = define("", APIResponse{})
+	assert.Equal(t, "object", v["swaggerAPIResponse"].Properties["data"].Type)
+}
+
+func TestRegisterPolymorphicPanics(t *testing.T) {
+	assert.Panics(t, func() { RegisterPolymorphic(Card{}, "method") })
+	assert.Panics(t, func() { RegisterPolymorphic((*Payment)(nil), "") })
+	assert.Panics(t, func() { RegisterPolymorphic((*Payment)(nil), "method", Implements("person", Person{})) })
+}
@@ -0,0 +1,65 @@
+package swagger
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type used for RFC 7807 problem responses
+const ProblemContentType = "application/problem+json"
+
+// StandardErrorCodes lists the error statuses declared by endpoint.StandardErrors
+var StandardErrorCodes = []int{
+	http.StatusBadRequest,
+	http.StatusUnauthorized,
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusConflict,
+	http.StatusUnprocessableEntity,
+	http.StatusInternalServerError,
+}
+
+// ProblemDetails represents an RFC 7807 problem details object
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty" desc:"URI reference that identifies the problem type"`
+	Title    string `json:"title,omitempty" desc:"short, human-readable summary of the problem type"`
+	Status   int    `json:"status,omitempty" desc:"HTTP status code generated by the origin server"`
+	Detail   string `json:"detail,omitempty" desc:"human-readable explanation specific to this occurrence"`
+	Instance string `json:"instance,omitempty" desc:"URI reference that identifies this occurrence"`
+}
+
+// NewProblem constructs a ProblemDetails for the specified status; title defaults to the standard status text
+func NewProblem(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Write writes the problem to w as application/problem+json using the problem's status
+func (p *ProblemDetails) Write(w http.ResponseWriter) error {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// WriteProblem is a convenience for handlers that writes a problem response with the specified status and detail
+func WriteProblem(w http.ResponseWriter, status int, detail string) error {
+	return NewProblem(status, detail).Write(w)
+}
+
+// ProblemResponse returns a response whose schema is ProblemDetails
+func ProblemResponse(description string) Response {
+	return Response{
+		Description: description,
+		Schema:      MakeSchema("", ProblemDetails{}),
+		MediaTypes:  []string{ProblemContentType},
+	}
+}
@@ -0,0 +1,64 @@
+package swagger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := WriteProblem(w, http.StatusNotFound, "pet not found")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	p := ProblemDetails{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "pet not found", p.Detail)
+}
+
+func TestProblemResponse(t *testing.T) {
+	r := ProblemResponse("error")
+	assert.Equal(t, "error", r.Description)
+	assert.Equal(t, "#/definitions/swaggerProblemDetails", r.Schema.Ref)
+
+	obj := define("", ProblemDetails{})["swaggerProblemDetails"]
+	assert.Len(t, obj.Properties, 5)
+	assert.Equal(t, "integer", obj.Properties["status"].Type)
+}
+
+func TestDefaultResponse(t *testing.T) {
+	r := ProblemResponse("error")
+	api := &API{DefaultResponse: &r}
+
+	e := &Endpoint{Method: "GET", Path: "/pets"}
+	api.AddEndpoint(e)
+	assert.Equal(t, "error", api.Paths["/pets"].Get.Responses["default"].Description)
+	assert.Contains(t, api.Definitions, "swaggerProblemDetails")
+	assert.Nil(t, e.Responses, "the caller's endpoint is left alone")
+
+	custom := &Endpoint{Method: "GET", Path: "/owners", Responses: map[string]Response{"default": {Description: "custom"}}}
+	api.AddEndpoint(custom)
+	assert.Equal(t, "custom", api.Paths["/owners"].Get.Responses["default"].Description)
+}
+
+func TestDefaultResponseKeepsSnapshots(t *testing.T) {
+	api := &API{}
+	api.AddEndpoint(&Endpoint{Method: "GET", Path: "/pets", Responses: map[string]Response{"200": {Description: "ok"}}})
+	before := api.Snapshot()
+
+	// re-adding an endpoint, as the DefaultError option does, must not modify the endpoint of earlier snapshots
+	r := ProblemResponse("error")
+	api.DefaultResponse = &r
+	api.AddEndpoint(api.Paths["/pets"].Get)
+
+	assert.NotContains(t, before.Paths["/pets"].Get.Responses, "default")
+	assert.Contains(t, api.Snapshot().Paths["/pets"].Get.Responses, "default")
+}
@@ -0,0 +1,374 @@
+// Package reference renders a swagger.API as a human readable Markdown or static HTML api reference
+package reference
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Document is the view of the api passed to the reference templates
+type Document struct {
+	API         *swagger.API
+	Groups      []Group
+	Definitions []Definition
+}
+
+// Group contains the operations associated with a tag; operations without tags are grouped under "default"
+type Group struct {
+	Tag        swagger.Tag
+	Operations []Operation
+}
+
+// Operation describes a single endpoint
+type Operation struct {
+	Method     string
+	Path       string
+	Endpoint   *swagger.Endpoint
+	Parameters []Parameter
+	Body       *Body
+	Responses  []Response
+	Security   []string
+}
+
+// Parameter describes a non-body parameter
+type Parameter struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Deprecated  bool
+	Description string
+}
+
+// Body describes the request body
+type Body struct {
+	Description string
+	Required    bool
+	Type        string
+	Fields      []Field
+	Examples    []Example
+}
+
+// Response describes a response for a single status code
+type Response struct {
+	Code        string
+	Description string
+	Type        string
+	MediaTypes  []string
+	Fields      []Field
+	Headers     []Field
+	Examples    []Example
+}
+
+// Definition describes a model from the api's definitions
+type Definition struct {
+	Name        string
+	Description string
+	Fields      []Field
+
+	// Discriminator and Implementations describe a polymorphic model; see swagger.RegisterPolymorphic
+	Discriminator   string
+	Implementations []string
+}
+
+// Field describes a property of a model
+type Field struct {
+	Name        string
+	Type        string
+	Required    bool
+	Deprecated  bool
+	Description string
+
+	// Ref is the name of the definition the field refers to, if any
+	Ref string
+}
+
+// Example is an example body for a media type formatted for display
+type Example struct {
+	MediaType string
+	Value     interface{}
+}
+
+// NewDocument builds the template view of the api
+func NewDocument(api *swagger.API) *Document {
+	d := &Document{API: api.Snapshot()}
+	api = d.API
+
+	byTag := map[string]*Group{}
+	var groups []*Group
+	group := func(name string) *Group {
+		if g, ok := byTag[name]; ok {
+			return g
+		}
+		g := &Group{Tag: swagger.Tag{Name: name}}
+		byTag[name] = g
+		groups = append(groups, g)
+		return g
+	}
+	for _, tag := range api.Tags {
+		group(tag.Name).Tag = tag
+	}
+
+	for _, o := range api.Operations() {
+		e := o.Endpoint
+		op := d.operation(o.Method, path.Join(api.BasePath, o.Path), e)
+		tags := e.Tags
+		if len(tags) == 0 {
+			tags = []string{"default"}
+		}
+		for _, tag := range tags {
+			g := group(tag)
+			g.Operations = append(g.Operations, op)
+		}
+	}
+
+	for _, g := range groups {
+		if len(g.Operations) > 0 {
+			d.Groups = append(d.Groups, *g)
+		}
+	}
+
+	names := make([]string, 0, len(api.Definitions))
+	for name, obj := range api.Definitions {
+		if obj.Type == "object" || len(obj.AllOf) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		obj := api.Definitions[name]
+		definition := Definition{
+			Name:          name,
+			Description:   obj.Description,
+			Fields:        d.fields(obj),
+			Discriminator: obj.Discriminator,
+		}
+		if obj.Discriminator != "" {
+			definition.Implementations = swagger.Implementations(api.Definitions, name)
+		}
+		d.Definitions = append(d.Definitions, definition)
+	}
+
+	return d
+}
+
+func (d *Document) operation(method, urlPath string, e *swagger.Endpoint) Operation {
+	op := Operation{
+		Method:   method,
+		Path:     urlPath,
+		Endpoint: e,
+		Security: d.security(e),
+	}
+
+	for _, p := range e.Parameters {
+		if p.In != "body" {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        p.Name,
+				In:          p.In,
+				Type:        p.Type,
+				Required:    p.Required,
+				Deprecated:  p.Deprecated,
+				Description: p.Description,
+			})
+			continue
+		}
+
+		body := &Body{
+			Description: p.Description,
+			Required:    p.Required,
+			Examples:    examples(p.Examples),
+		}
+		if p.Schema != nil {
+			body.Type = d.schemaType(p.Schema)
+			body.Fields = d.schemaFields(p.Schema)
+			if len(body.Examples) == 0 {
+				body.Examples = []Example{{MediaType: "application/json", Value: swagger.SchemaExample(p.Schema, d.API.Definitions)}}
+			}
+		}
+		op.Body = body
+	}
+
+	codes := make([]string, 0, len(e.Responses))
+	for code := range e.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		r := e.Responses[code]
+		response := Response{
+			Code:        code,
+			Description: r.Description,
+			MediaTypes:  r.MediaTypes,
+			Examples:    examples(r.Examples),
+		}
+		if r.Schema != nil {
+			response.Type = d.schemaType(r.Schema)
+			response.Fields = d.schemaFields(r.Schema)
+
+			mediaType := "application/json"
+			if len(r.MediaTypes) > 0 {
+				mediaType = r.MediaTypes[0]
+			}
+			if len(response.Examples) == 0 && strings.Contains(mediaType, "json") {
+				response.Examples = []Example{{MediaType: mediaType, Value: swagger.SchemaExample(r.Schema, d.API.Definitions)}}
+			}
+		}
+
+		headers := make([]string, 0, len(r.Headers))
+		for name := range r.Headers {
+			headers = append(headers, name)
+		}
+		sort.Strings(headers)
+		for _, name := range headers {
+			h := r.Headers[name]
+			response.Headers = append(response.Headers, Field{Name: name, Type: h.Type, Description: h.Description})
+		}
+
+		op.Responses = append(op.Responses, response)
+	}
+
+	return op
+}
+
+func examples(values map[string]interface{}) []Example {
+	mediaTypes := make([]string, 0, len(values))
+	for mediaType := range values {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	var v []Example
+	for _, mediaType := range mediaTypes {
+		v = append(v, Example{MediaType: mediaType, Value: values[mediaType]})
+	}
+	return v
+}
+
+// security lists the effective security requirements of the endpoint; each entry is one alternative
+func (d *Document) security(e *swagger.Endpoint) []string {
+	requirement := d.API.EffectiveSecurity(e)
+	if requirement == nil {
+		return nil
+	}
+
+	var alternatives []string
+	for _, r := range requirement.Requirements {
+		names := make([]string, 0, len(r))
+		for name, scopes := range r {
+			if len(scopes) > 0 {
+				name += " (" + strings.Join(scopes, ", ") + ")"
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		alternatives = append(alternatives, strings.Join(names, " and "))
+	}
+	return alternatives
+}
+
+func (d *Document) schemaType(schema *swagger.Schema) string {
+	switch {
+	case schema.Ref != "":
+		return d.refType(schema.Ref)
+	case schema.Type == "array" && schema.Items != nil:
+		return "array of " + d.itemsType(schema.Items)
+	default:
+		return schema.Type
+	}
+}
+
+// schemaFields expands the fields of the object the schema refers to, if any
+func (d *Document) schemaFields(schema *swagger.Schema) []Field {
+	ref := schema.Ref
+	if ref == "" && schema.Items != nil {
+		ref = schema.Items.Ref
+	}
+
+	obj, ok := d.API.Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if !ok || obj.Type != "object" && len(obj.AllOf) == 0 {
+		return nil
+	}
+	return d.fields(obj)
+}
+
+func (d *Document) fields(obj swagger.Object) []Field {
+	obj = swagger.Flatten(obj, d.API.Definitions)
+
+	names := make([]string, 0, len(obj.Properties))
+	for name := range obj.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := map[string]bool{}
+	for _, name := range obj.Required {
+		required[name] = true
+	}
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		p := obj.Properties[name]
+		fields = append(fields, Field{
+			Name:        name,
+			Type:        d.propertyType(p),
+			Required:    required[name],
+			Deprecated:  p.Deprecated,
+			Description: p.Description,
+			Ref:         d.propertyRef(p),
+		})
+	}
+	return fields
+}
+
+func (d *Document) refType(ref string) string {
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	if obj, ok := d.API.Definitions[name]; ok && obj.Type != "object" && len(obj.AllOf) == 0 {
+		return obj.Type
+	}
+	return name
+}
+
+func (d *Document) itemsType(items *swagger.Items) string {
+	if items.Ref != "" {
+		return d.refType(items.Ref)
+	}
+	return items.Type
+}
+
+func (d *Document) propertyType(p swagger.Property) string {
+	switch {
+	case p.Ref != "":
+		return d.refType(p.Ref)
+	case p.Type == "array" && p.Items != nil:
+		return "array of " + d.itemsType(p.Items)
+	case p.AdditionalProperties != nil:
+		return "map of " + d.itemsType(p.AdditionalProperties)
+	case len(p.Enum) > 0:
+		return fmt.Sprintf("%v (%v)", p.Type, strings.Join(p.Enum, ", "))
+	case p.Format != "":
+		return p.Type + " (" + p.Format + ")"
+	default:
+		return p.Type
+	}
+}
+
+func (d *Document) propertyRef(p swagger.Property) string {
+	ref := p.Ref
+	switch {
+	case p.Items != nil:
+		ref = p.Items.Ref
+	case p.AdditionalProperties != nil:
+		ref = p.AdditionalProperties.Ref
+	}
+
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	if obj, ok := d.API.Definitions[name]; ok && (obj.Type == "object" || len(obj.AllOf) > 0) {
+		return name
+	}
+	return ""
+}
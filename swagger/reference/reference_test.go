@@ -0,0 +1,156 @@
+package reference
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type Category struct {
+	Name string `json:"name" desc:"category name"`
+}
+
+type Pet struct {
+	ID       int64     `json:"id" required:"true"`
+	Name     string    `json:"name" required:"true" desc:"the pet's name" example:"fido"`
+	Status   string    `json:"status" enum:"available,sold" desc:"sale | adoption status"`
+	Category *Category `json:"category"`
+}
+
+func petstore() *swagger.API {
+	api := &swagger.API{
+		Swagger:  "2.0",
+		BasePath: "/api",
+		Host:     "pets.example.com",
+		Info:     swagger.Info{Title: "Petstore", Description: "Manages pets", Version: "1.0"},
+		Tags: []swagger.Tag{
+			{Name: "pets", Description: "Everything about pets", Docs: &swagger.Docs{Description: "Find out more", URL: "https://example.com/pets"}},
+		},
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{{"api_key": {}}, {"oauth": {"read", "write"}}}},
+	}
+
+	api.AddEndpoint(endpoint.Get("/pets/{id}", "Find a pet",
+		endpoint.Tags("pets"),
+		endpoint.Description("Returns a single pet"),
+		endpoint.Path("id", "integer", "pet id", true),
+		endpoint.Response(http.StatusOK, Pet{}, "", "the pet",
+			endpoint.Header("X-Rate-Limit", "integer", "int32", "calls per hour"),
+		),
+		endpoint.Errors(http.StatusNotFound),
+	))
+	api.AddEndpoint(endpoint.Post("/pets", "Create a pet",
+		endpoint.Tags("pets"),
+		endpoint.Body(Pet{}, "the pet to create", true),
+		endpoint.BodyExample("application/json", map[string]interface{}{"name": "rex"}),
+		endpoint.Response(http.StatusCreated, Pet{}, "", "created"),
+	))
+	api.AddEndpoint(endpoint.Get("/health", "Health check", endpoint.NoSecurity()))
+
+	return api
+}
+
+func golden(t *testing.T, name string, actual []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.Nil(t, ioutil.WriteFile(path, actual, 0644))
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestMarkdown(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Markdown(buf, petstore()))
+	golden(t, "petstore.md", buf.Bytes())
+}
+
+func TestHTML(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, HTML(buf, petstore()))
+	golden(t, "petstore.html", buf.Bytes())
+}
+
+func TestMarkdownTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("custom").Funcs(Funcs).Parse(
+		`{{range .Groups}}{{.Tag.Name}}:{{range .Operations}} {{.Method}} {{.Path}}{{end}};{{end}}`,
+	))
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Markdown(buf, petstore(), MarkdownTemplate(tmpl)))
+	assert.Equal(t, "pets: POST /api/pets GET /api/pets/{id};default: GET /api/health;", buf.String())
+}
+
+func TestNewDocument(t *testing.T) {
+	d := NewDocument(petstore())
+	assert.Len(t, d.Groups, 2)
+
+	create := d.Groups[0].Operations[0]
+	assert.Equal(t, map[string]interface{}{"name": "rex"}, create.Body.Examples[0].Value)
+
+	get := d.Groups[0].Operations[1]
+	assert.Equal(t, []string{"api_key", "oauth (read, write)"}, get.Security)
+	assert.Equal(t, "referencePet", get.Responses[0].Type)
+	assert.Equal(t, "referenceCategory", get.Responses[0].Fields[0].Ref)
+
+	health := d.Groups[1].Operations[0]
+	assert.Nil(t, health.Security)
+}
+
+type Animal interface {
+	animal()
+}
+
+type Dog struct {
+	Barks bool `json:"barks"`
+}
+
+func (Dog) animal() {}
+
+type Cat struct {
+	Lives int `json:"lives"`
+}
+
+func (Cat) animal() {}
+
+func TestPolymorphicDefinitions(t *testing.T) {
+	swagger.RegisterPolymorphic((*Animal)(nil), "type",
+		swagger.Implements("dog", Dog{}),
+		swagger.Implements("cat", Cat{}),
+	)
+
+	api := &swagger.API{Swagger: "2.0"}
+	api.AddEndpoint(endpoint.Get("/animals/{id}", "Find an animal",
+		endpoint.Path("id", "integer", "animal id", true),
+		endpoint.ResponseType(http.StatusOK, reflect.TypeOf((*Animal)(nil)), "", "the animal"),
+	))
+
+	d := NewDocument(api)
+	assert.Len(t, d.Definitions, 3)
+
+	animal := d.Definitions[0]
+	assert.Equal(t, "referenceAnimal", animal.Name)
+	assert.Equal(t, "type", animal.Discriminator)
+	assert.Equal(t, []string{"referenceCat", "referenceDog"}, animal.Implementations)
+
+	cat := d.Definitions[1]
+	assert.Equal(t, "lives", cat.Fields[0].Name)
+	assert.Equal(t, "type", cat.Fields[1].Name)
+	assert.Equal(t, "string (cat)", cat.Fields[1].Type)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Markdown(buf, api))
+	assert.Contains(t, buf.String(), "One of: [referenceCat](#referencecat), [referenceDog](#referencedog)")
+}
@@ -0,0 +1,83 @@
+package reference
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Option provides configuration options to the renderers
+type Option func(r *renderer)
+
+type renderer struct {
+	markdown *template.Template
+	html     *htmltemplate.Template
+}
+
+// MarkdownTemplate replaces the template used by Markdown; the template is executed with a *Document and may use the
+// functions in Funcs
+func MarkdownTemplate(t *template.Template) Option {
+	return func(r *renderer) {
+		r.markdown = t
+	}
+}
+
+// HTMLTemplate replaces the template used by HTML; the template is executed with a *Document and may use the
+// functions in Funcs
+func HTMLTemplate(t *htmltemplate.Template) Option {
+	return func(r *renderer) {
+		r.html = t
+	}
+}
+
+var reAnchor = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Funcs are the helper functions available to the reference templates
+var Funcs = map[string]interface{}{
+	"anchor": func(v string) string {
+		return strings.Trim(reAnchor.ReplaceAllString(strings.ToLower(v), "-"), "-")
+	},
+	"cell": func(v string) string {
+		return strings.Replace(strings.Replace(v, "|", `\|`, -1), "\n", " ", -1)
+	},
+	"json": func(v interface{}) string {
+		if s, ok := v.(string); ok {
+			return s
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err.Error()
+		}
+		return string(data)
+	},
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+}
+
+func newRenderer(options []Option) *renderer {
+	r := &renderer{
+		markdown: template.Must(template.New("markdown").Funcs(Funcs).Parse(DefaultMarkdownTemplate)),
+		html:     htmltemplate.Must(htmltemplate.New("html").Funcs(Funcs).Parse(DefaultHTMLTemplate)),
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Markdown writes a Markdown reference for the api grouped by tag
+func Markdown(w io.Writer, api *swagger.API, options ...Option) error {
+	return newRenderer(options).markdown.Execute(w, NewDocument(api))
+}
+
+// HTML writes a single file, static HTML reference for the api grouped by tag
+func HTML(w io.Writer, api *swagger.API, options ...Option) error {
+	return newRenderer(options).html.Execute(w, NewDocument(api))
+}
@@ -0,0 +1,147 @@
+package reference
+
+// DefaultMarkdownTemplate is the template used by Markdown unless replaced with MarkdownTemplate
+const DefaultMarkdownTemplate = `{{define "fields"}}{{if .}}
+| Field | Type | Required | Description |
+| --- | --- | --- | --- |
+{{range .}}| {{.Name}}{{if .Deprecated}} (deprecated){{end}} | {{if .Ref}}[{{.Type}}](#{{anchor .Ref}}){{else}}{{.Type}}{{end}} | {{if .Required}}yes{{else}}no{{end}} | {{cell .Description}} |
+{{end}}{{end}}{{end}}
+{{- define "examples"}}{{range .}}
+Example ({{.MediaType}}):
+
+` + "```" + `
+{{json .Value}}
+` + "```" + `
+{{end}}{{end}}
+{{- with .API.Info}}# {{.Title}}
+{{with .Description}}
+{{.}}
+{{end}}
+{{with .Version}}Version: {{.}}
+{{end}}{{end}}{{with .API.Host}}Host: {{.}}
+{{end}}{{with .API.BasePath}}Base path: ` + "`{{.}}`" + `
+{{end}}
+{{- range .Groups}}
+## {{.Tag.Name}}
+{{with .Tag.Description}}
+{{.}}
+{{end}}{{with .Tag.Docs}}
+See [{{or .Description .URL}}]({{.URL}})
+{{end}}
+{{- range .Operations}}
+### {{.Method}} {{.Path}}
+{{with .Endpoint.Summary}}
+{{.}}
+{{end}}{{with .Endpoint.Description}}
+{{.}}
+{{end}}{{if .Endpoint.Deprecated}}
+**Deprecated**{{if not .Endpoint.Sunset.IsZero}}, sunset {{.Endpoint.Sunset.Format "2006-01-02"}}{{end}}{{with .Endpoint.Successor}}, use {{.}} instead{{end}}
+{{end}}{{if .Security}}
+Security: {{join .Security " or "}}
+{{end}}{{if .Parameters}}
+#### Parameters
+
+| Name | In | Type | Required | Description |
+| --- | --- | --- | --- | --- |
+{{range .Parameters}}| {{.Name}}{{if .Deprecated}} (deprecated){{end}} | {{.In}} | {{.Type}} | {{if .Required}}yes{{else}}no{{end}} | {{cell .Description}} |
+{{end}}{{end}}{{with .Body}}
+#### Request body
+
+{{if .Type}}` + "`{{.Type}}`" + `{{if .Required}} (required){{end}}{{if .Description}} - {{.Description}}{{end}}
+{{end}}{{template "fields" .Fields}}{{template "examples" .Examples}}{{end}}{{if .Responses}}
+#### Responses
+{{range .Responses}}
+##### {{.Code}}{{with .Description}} {{.}}{{end}}
+{{with .Type}}
+` + "`{{.}}`" + `
+{{end}}{{with .MediaTypes}}
+Content type: {{join . ", "}}
+{{end}}{{template "fields" .Fields}}{{if .Headers}}
+| Header | Type | Description |
+| --- | --- | --- |
+{{range .Headers}}| {{.Name}} | {{.Type}} | {{cell .Description}} |
+{{end}}{{end}}{{template "examples" .Examples}}{{end}}{{end}}{{end}}{{end}}
+{{- if .Definitions}}
+## Models
+{{range .Definitions}}
+### {{.Name}}
+{{with .Description}}
+{{.}}
+{{end}}{{with .Discriminator}}
+Discriminated by ` + "`{{.}}`" + `
+{{end}}{{with .Implementations}}
+One of: {{range $i, $name := .}}{{if $i}}, {{end}}[{{$name}}](#{{anchor $name}}){{end}}
+{{end}}{{template "fields" .Fields}}{{end}}{{end}}`
+
+// DefaultHTMLTemplate is the template used by HTML unless replaced with HTMLTemplate
+const DefaultHTMLTemplate = `{{define "fields"}}{{if .}}
+<table>
+<tr><th>Field</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{range .}}<tr><td><code>{{.Name}}</code>{{if .Deprecated}} <span class="deprecated">deprecated</span>{{end}}</td><td>{{if .Ref}}<a href="#{{anchor .Ref}}">{{.Type}}</a>{{else}}{{.Type}}{{end}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+{{- define "examples"}}{{range .}}
+<p class="example">Example ({{.MediaType}})</p>
+<pre>{{json .Value}}</pre>
+{{end}}{{end}}
+{{- with .API.Info}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; max-width: 960px; margin: 0 auto; padding: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: 6px 8px; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+.operation { border-top: 1px solid #eee; padding-top: 1em; }
+.method { display: inline-block; min-width: 4em; font-weight: bold; text-transform: uppercase; }
+.deprecated { color: #b00; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Description}}<p>{{.}}</p>
+{{end}}{{with .Version}}<p>Version: {{.}}</p>
+{{end}}{{end}}{{with .API.Host}}<p>Host: {{.}}</p>
+{{end}}{{with .API.BasePath}}<p>Base path: <code>{{.}}</code></p>
+{{end}}
+{{- range .Groups}}
+<h2 id="{{anchor .Tag.Name}}">{{.Tag.Name}}</h2>
+{{with .Tag.Description}}<p>{{.}}</p>
+{{end}}{{with .Tag.Docs}}<p>See <a href="{{.URL}}">{{or .Description .URL}}</a></p>
+{{end}}
+{{- range .Operations}}
+<div class="operation">
+<h3><span class="method">{{.Method}}</span> <code>{{.Path}}</code></h3>
+{{with .Endpoint.Summary}}<p>{{.}}</p>
+{{end}}{{with .Endpoint.Description}}<p>{{.}}</p>
+{{end}}{{if .Endpoint.Deprecated}}<p class="deprecated">Deprecated{{if not .Endpoint.Sunset.IsZero}}, sunset {{.Endpoint.Sunset.Format "2006-01-02"}}{{end}}{{with .Endpoint.Successor}}, use <a href="{{.}}">{{.}}</a> instead{{end}}</p>
+{{end}}{{if .Security}}<p>Security: {{join .Security " or "}}</p>
+{{end}}{{if .Parameters}}<h4>Parameters</h4>
+<table>
+<tr><th>Name</th><th>In</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{range .Parameters}}<tr><td><code>{{.Name}}</code>{{if .Deprecated}} <span class="deprecated">deprecated</span>{{end}}</td><td>{{.In}}</td><td>{{.Type}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{with .Body}}<h4>Request body</h4>
+{{if .Type}}<p><code>{{.Type}}</code>{{if .Required}} (required){{end}}{{if .Description}} - {{.Description}}{{end}}</p>
+{{end}}{{template "fields" .Fields}}{{template "examples" .Examples}}{{end}}{{if .Responses}}<h4>Responses</h4>
+{{range .Responses}}<h5>{{.Code}}{{with .Description}} {{.}}{{end}}</h5>
+{{with .Type}}<p><code>{{.}}</code></p>
+{{end}}{{with .MediaTypes}}<p>Content type: {{join . ", "}}</p>
+{{end}}{{template "fields" .Fields}}{{if .Headers}}<table>
+<tr><th>Header</th><th>Type</th><th>Description</th></tr>
+{{range .Headers}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{template "examples" .Examples}}{{end}}{{end}}</div>
+{{end}}{{end}}
+{{- if .Definitions}}
+<h2>Models</h2>
+{{range .Definitions}}<h3 id="{{anchor .Name}}">{{.Name}}</h3>
+{{with .Description}}<p>{{.}}</p>
+{{end}}{{with .Discriminator}}<p>Discriminated by <code>{{.}}</code></p>
+{{end}}{{with .Implementations}}<p>One of: {{range $i, $name := .}}{{if $i}}, {{end}}<a href="#{{anchor $name}}">{{$name}}</a>{{end}}</p>
+{{end}}{{template "fields" .Fields}}{{end}}{{end}}
+</body>
+</html>
+`
@@ -1,7 +1,10 @@
 package swagger
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -49,41 +52,214 @@ func inspect(t reflect.Type, jsonTag string) Property {
 
 	case reflect.Slice:
 		p.Type = "array"
-		p.Items = &Items{}
+		p.Items, p.GoType = inspectItems(t.Elem())
 
-		p.GoType = t.Elem() // dereference the slice
-		switch p.GoType.Kind() {
-		case reflect.Ptr:
-			p.GoType = p.GoType.Elem()
-			name := makeName(p.GoType)
-			p.Items.Ref = makeRef(name)
+	case reflect.Map:
+		p.Type = "object"
+		p.AdditionalProperties, p.GoType = inspectItems(t.Elem())
 
-		case reflect.Struct:
-			name := makeName(p.GoType)
-			p.Items.Ref = makeRef(name)
+	case reflect.Interface:
+		if _, ok := lookupPolymorphic(t); ok {
+			p.Ref = makeRef(makeName(t))
+		} else {
+			p.Type = "object"
+		}
+	}
+
+	return p
+}
 
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
-			p.Items.Type = "integer"
-			p.Items.Format = "int32"
+// inspectItems describes the element type of a slice or map; returns the dereferenced element type
+func inspectItems(t reflect.Type) (*Items, reflect.Type) {
+	items := &Items{}
 
-		case reflect.Int64, reflect.Uint64:
-			p.Items.Type = "integer"
-			p.Items.Format = "int64"
+	switch t.Kind() {
+	case reflect.Ptr:
+		t = t.Elem()
+		name := makeName(t)
+		items.Ref = makeRef(name)
+
+	case reflect.Struct:
+		name := makeName(t)
+		items.Ref = makeRef(name)
 
-		case reflect.Float64:
-			p.Items.Type = "number"
-			p.Items.Format = "double"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		items.Type = "integer"
+		items.Format = "int32"
 
-		case reflect.Float32:
-			p.Items.Type = "number"
-			p.Items.Format = "float"
+	case reflect.Int64, reflect.Uint64:
+		items.Type = "integer"
+		items.Format = "int64"
 
-		case reflect.String:
-			p.Items.Type = "string"
+	case reflect.Float64:
+		items.Type = "number"
+		items.Format = "double"
+
+	case reflect.Float32:
+		items.Type = "number"
+		items.Format = "float"
+
+	case reflect.Bool:
+		items.Type = "boolean"
+
+	case reflect.String:
+		items.Type = "string"
+
+	case reflect.Interface:
+		if _, ok := lookupPolymorphic(t); ok {
+			items.Ref = makeRef(makeName(t))
+		} else {
+			items.Type = "object"
 		}
 	}
 
-	return p
+	return items, t
+}
+
+// tagVariants returns the values of the struct tags beginning with prefix keyed by the remainder of the tag name e.g.
+// desc_zh:"名称" => {"zh": "名称"}
+func tagVariants(tag reflect.StructTag, prefix string) map[string]string {
+	var variants map[string]string
+
+	for tag != "" {
+		// tags are formatted as key:"value" pairs separated by spaces; see reflect.StructTag.Lookup
+		s := strings.TrimLeft(string(tag), " ")
+		i := strings.Index(s, ":\"")
+		if i <= 0 {
+			break
+		}
+		key := s[:i]
+
+		j := i + 2
+		for j < len(s) && s[j] != '"' {
+			if s[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) {
+			break
+		}
+		tag = reflect.StructTag(s[j+1:])
+
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		value, err := strconv.Unquote(s[i+1 : j+1])
+		if err != nil {
+			continue
+		}
+		if variants == nil {
+			variants = map[string]string{}
+		}
+		variants[key[len(prefix):]] = value
+	}
+
+	return variants
+}
+
+func itemsType(p Property) string {
+	if p.Items == nil {
+		return ""
+	}
+	return p.Items.Type
+}
+
+// setBounds sets the minimum, maximum, minLength and maxLength of the property from the field's struct tags; panics if
+// a tag isn't a number
+func setBounds(p *Property, t reflect.Type, field reflect.StructField) {
+	number := func(tag string) *float64 {
+		v, ok := field.Tag.Lookup(tag)
+		if !ok {
+			return nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			panic(fmt.Errorf("%v.%v: %v tag must be a number; got %q", t.Name(), field.Name, tag, v))
+		}
+		return &f
+	}
+	length := func(tag string) *int {
+		v, ok := field.Tag.Lookup(tag)
+		if !ok {
+			return nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			panic(fmt.Errorf("%v.%v: %v tag must be a non-negative integer; got %q", t.Name(), field.Name, tag, v))
+		}
+		return &n
+	}
+
+	p.Minimum, p.Maximum = number("minimum"), number("maximum")
+	p.MinLength, p.MaxLength = length("minLength"), length("maxLength")
+}
+
+// parseExample converts the value of an example struct tag into a value of the property's type; arrays accept either
+// a json array or a comma separated list, and objects and references accept json.  Returns an error if the value
+// can't be converted
+func parseExample(typ, itemType, v string) (interface{}, error) {
+	var err error
+	switch typ {
+	case "integer":
+		var i int64
+		if i, err = strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+	case "number":
+		var f float64
+		if f, err = strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+	case "boolean":
+		var b bool
+		if b, err = strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	case "string":
+		return v, nil
+	case "array":
+		var values []interface{}
+		if json.Unmarshal([]byte(v), &values) == nil {
+			return values, nil
+		}
+		values = []interface{}{}
+		for _, item := range strings.Split(v, ",") {
+			value, err := parseExample(itemType, "", strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		var value interface{}
+		if err = json.Unmarshal([]byte(v), &value); err == nil {
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%q isn't a valid %v", v, exampleType(typ, itemType))
+}
+
+func exampleType(typ, itemType string) string {
+	switch {
+	case typ == "array" && itemType != "":
+		return "array of " + itemType
+	case typ == "":
+		return "json object"
+	}
+	return typ
+}
+
+// parseExtension converts the value of an x- struct tag from json, keeping values that aren't json as strings
+func parseExtension(v string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(v), &value); err == nil {
+		return value
+	}
+	return v
 }
 
 func defineObject(name string, v interface{}) Object {
@@ -107,6 +283,18 @@ func defineObject(name string, v interface{}) Object {
 		t = t.Elem()
 	}
 
+	if t.Kind() == reflect.Interface {
+		if _, ok := lookupPolymorphic(t); ok {
+			// the definitions themselves are added by definePolymorphic
+			return Object{
+				IsArray: isArray,
+				GoType:  t,
+				Type:    "object",
+				Name:    makeName(t),
+			}
+		}
+	}
+
 	if t.Kind() != reflect.Struct {
 		p := inspect(t, "")
 		return Object{
@@ -128,7 +316,14 @@ func defineObject(name string, v interface{}) Object {
 		}
 
 		if field.Type.Kind() == reflect.Interface {
-			field.Type = reflect.ValueOf(v).Field(i).Elem().Type()
+			if _, ok := lookupPolymorphic(field.Type); !ok {
+				// document the actual type of the prototype's value when there is one
+				if value := reflect.Indirect(reflect.ValueOf(v)); value.IsValid() && value.Type() == t {
+					if elem := value.Field(i); !elem.IsNil() {
+						field.Type = elem.Elem().Type()
+					}
+				}
+			}
 		}
 
 		// determine the json name of the field
@@ -166,6 +361,43 @@ func defineObject(name string, v interface{}) Object {
 
 		p := inspect(field.Type, field.Tag.Get("json"))
 		p.Description = field.Tag.Get("desc")
+		if p.Description == "" {
+			p.Description = fieldDescription(t, field.Name)
+		}
+		for locale, v := range tagVariants(field.Tag, "desc_") {
+			if p.Descriptions == nil {
+				p.Descriptions = map[string]string{}
+			}
+			p.Descriptions[normalizeLocale(locale)] = v
+		}
+		for name, v := range tagVariants(field.Tag, "x-") {
+			if p.Extensions == nil {
+				p.Extensions = map[string]interface{}{}
+			}
+			p.Extensions["x-"+name] = parseExtension(v)
+		}
+		if v := field.Tag.Get("deprecated"); v == "true" {
+			p.Deprecated = true
+		}
+		for _, v := range strings.Split(field.Tag.Get("docs"), ",") {
+			if strings.TrimSpace(v) == "internal" {
+				if p.Extensions == nil {
+					p.Extensions = map[string]interface{}{}
+				}
+				p.Extensions[InternalExtension] = true
+			}
+		}
+		if v := field.Tag.Get("enum"); v != "" {
+			p.Enum = strings.Split(v, ",")
+		}
+		setBounds(&p, t, field)
+		if v, ok := field.Tag.Lookup("example"); ok {
+			example, err := parseExample(p.Type, itemsType(p), v)
+			if err != nil {
+				panic(fmt.Errorf("%v.%v: example tag %v", t.Name(), field.Name, err))
+			}
+			p.Example = example
+		}
 		properties[name] = p
 	}
 
@@ -174,12 +406,14 @@ func defineObject(name string, v interface{}) Object {
 	}
 
 	return Object{
-		IsArray:    isArray,
-		GoType:     t,
-		Type:       "object",
-		Name:       name,
-		Required:   required,
-		Properties: properties,
+		IsArray:     isArray,
+		GoType:      t,
+		Type:        "object",
+		Name:        name,
+		Description: typeDescription(t),
+		Required:    required,
+		Properties:  properties,
+		Extensions:  typeExtensions(t),
 	}
 }
 
@@ -187,26 +421,38 @@ func define(alias string, v interface{}) map[string]Object {
 	objMap := map[string]Object{}
 
 	obj := defineObject(alias, v)
-	objMap[obj.Name] = obj
+	if obj.Name != makeName(obj.GoType) || !definePolymorphic(polymorphicBase(obj.GoType), objMap) {
+		objMap[obj.Name] = obj
+	}
 
 	dirty := true
 
 	for dirty {
 		dirty = false
 		for _, d := range objMap {
-			for _, p := range d.Properties {
-				switch p.GoType.Kind() {
-				case reflect.Struct:
-					name := makeName(p.GoType)
-					if _, exists := objMap[name]; !exists {
-						child := defineObject("", p.GoType)
-						objMap[child.Name] = child
-						dirty = true
-					}
-				case reflect.Interface:
+			properties := []map[string]Property{d.Properties}
+			for _, member := range d.AllOf {
+				properties = append(properties, member.Properties)
+			}
 
+			for _, props := range properties {
+				for _, p := range props {
+					switch p.GoType.Kind() {
+					case reflect.Struct:
+						name := makeName(p.GoType)
+						if _, exists := objMap[name]; !exists {
+							if !definePolymorphic(polymorphicBase(p.GoType), objMap) {
+								child := defineObject("", p.GoType)
+								objMap[child.Name] = child
+							}
+							dirty = true
+						}
+					case reflect.Interface:
+						if definePolymorphic(p.GoType, objMap) {
+							dirty = true
+						}
+					}
 				}
-
 			}
 		}
 	}
@@ -174,3 +174,45 @@ func TestInterfaceActualType(t *testing.T) {
 	data = obj.Properties["data"]
 	assert.Equal(t, reflect.TypeOf(Person{}), data.GoType)
 }
+
+func TestMapAndEnum(t *testing.T) {
+	type Labeled struct {
+		Labels  map[string]string  `json:"labels"`
+		Friends map[string]*Person `json:"friends"`
+		Status  string             `json:"status" enum:"available,sold"`
+	}
+
+	v := define("", Labeled{})
+	obj, ok := v["swaggerLabeled"]
+	assert.True(t, ok)
+
+	labels := obj.Properties["labels"]
+	assert.Equal(t, "object", labels.Type)
+	assert.Equal(t, &Items{Type: "string"}, labels.AdditionalProperties)
+
+	friends := obj.Properties["friends"]
+	assert.Equal(t, &Items{Ref: "#/definitions/swaggerPerson"}, friends.AdditionalProperties)
+	assert.Contains(t, v, "swaggerPerson")
+
+	assert.Equal(t, []string{"available", "sold"}, obj.Properties["status"].Enum)
+}
+
+func TestBounds(t *testing.T) {
+	type Bounded struct {
+		Age  int    `json:"age" minimum:"0" maximum:"150"`
+		Name string `json:"name" minLength:"1" maxLength:"64"`
+	}
+
+	obj := define("", Bounded{})["swaggerBounded"]
+	min, max, minLength, maxLength := 0.0, 150.0, 1, 64
+	assert.Equal(t, &min, obj.Properties["age"].Minimum)
+	assert.Equal(t, &max, obj.Properties["age"].Maximum)
+	assert.Equal(t, &minLength, obj.Properties["name"].MinLength)
+	assert.Equal(t, &maxLength, obj.Properties["name"].MaxLength)
+	assert.Nil(t, obj.Properties["name"].Minimum)
+
+	type Invalid struct {
+		Name string `json:"name" maxLength:"many"`
+	}
+	assert.Panics(t, func() { define("", Invalid{}) })
+}
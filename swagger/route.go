@@ -0,0 +1,83 @@
+package swagger
+
+import (
+	"path"
+	"strings"
+)
+
+// matchPath compares a swagger path template, e.g. /pets/{id}, against a request path and returns the path parameters
+// along with the number of literal segments matched; ok is false when the path does not match
+func matchPath(template, urlPath string) (params map[string]string, literals int, ok bool) {
+	want := strings.Split(strings.Trim(template, "/"), "/")
+	got := strings.Split(strings.Trim(urlPath, "/"), "/")
+	if len(want) != len(got) {
+		return nil, 0, false
+	}
+
+	params = map[string]string{}
+	for i, segment := range want {
+		if isParam(segment) {
+			if got[i] == "" {
+				return nil, 0, false
+			}
+			params[segment[1:len(segment)-1]] = got[i]
+			continue
+		}
+
+		if segment != got[i] {
+			return nil, 0, false
+		}
+		literals++
+	}
+
+	return params, literals, true
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// preferred reports whether template a should serve a request that template b matches with as many literal segments:
+// the template whose first literal segment comes earlier wins, e.g. /pets/{id} over /{kind}/mine, then the lexically
+// smaller template
+func preferred(a, b string) bool {
+	as := strings.Split(strings.Trim(a, "/"), "/")
+	bs := strings.Split(strings.Trim(b, "/"), "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if literal := !isParam(as[i]); literal != !isParam(bs[i]) {
+			return literal
+		}
+	}
+	return a < b
+}
+
+// Lookup finds the endpoint that serves the specified method and request path; the request path is expected to
+// include the basePath.  Literal segments are preferred to path parameters when more than one path matches, and ties
+// are broken by preferred.  Returns nil if no endpoint matches
+func (a *API) Lookup(method, urlPath string) (*Endpoint, map[string]string) {
+	var (
+		found    *Endpoint
+		template string
+		params   map[string]string
+		best     = -1
+	)
+
+	a = a.Snapshot()
+
+	for rawPath, endpoints := range a.Paths {
+		e := endpoints.Method(method)
+		if e == nil {
+			continue
+		}
+
+		t := path.Join(a.BasePath, rawPath)
+		p, literals, ok := matchPath(t, urlPath)
+		if !ok || literals < best || literals == best && !preferred(t, template) {
+			continue
+		}
+
+		found, template, params, best = e, t, p, literals
+	}
+
+	return found, params
+}
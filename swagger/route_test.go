@@ -0,0 +1,62 @@
+package swagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	params, literals, ok := matchPath("/api/pets/{id}", "/api/pets/123")
+	assert.True(t, ok)
+	assert.Equal(t, 2, literals)
+	assert.Equal(t, map[string]string{"id": "123"}, params)
+
+	_, _, ok = matchPath("/api/pets/{id}", "/api/pets")
+	assert.False(t, ok)
+
+	_, _, ok = matchPath("/api/pets/{id}", "/api/owners/123")
+	assert.False(t, ok)
+}
+
+func TestLookup(t *testing.T) {
+	byID := &Endpoint{Method: "GET", Path: "/pets/{id}"}
+	mine := &Endpoint{Method: "GET", Path: "/pets/mine"}
+	create := &Endpoint{Method: "POST", Path: "/pets"}
+
+	api := &API{BasePath: "/api"}
+	api.AddEndpoint(byID)
+	api.AddEndpoint(mine)
+	api.AddEndpoint(create)
+
+	e, params := api.Lookup("GET", "/api/pets/123")
+	assert.Equal(t, byID, e)
+	assert.Equal(t, "123", params["id"])
+
+	e, _ = api.Lookup("get", "/api/pets/mine")
+	assert.Equal(t, mine, e)
+
+	e, _ = api.Lookup("POST", "/api/pets")
+	assert.Equal(t, create, e)
+
+	e, _ = api.Lookup("DELETE", "/api/pets/123")
+	assert.Nil(t, e)
+}
+
+func TestLookupTies(t *testing.T) {
+	byID := &Endpoint{Method: "GET", Path: "/pets/{id}"}
+	kind := &Endpoint{Method: "GET", Path: "/{kind}/mine"}
+	byName := &Endpoint{Method: "GET", Path: "/pets/{name}"}
+
+	api := &API{}
+	api.AddEndpoint(kind)
+	api.AddEndpoint(byName)
+	api.AddEndpoint(byID)
+
+	// map iteration order varies, so repeat the lookup to catch a nondeterministic choice
+	for i := 0; i < 20; i++ {
+		e, params := api.Lookup("GET", "/pets/mine")
+		assert.Equal(t, byID, e)
+		assert.Equal(t, map[string]string{"id": "mine"}, params)
+	}
+}
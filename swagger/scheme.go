@@ -0,0 +1,252 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// oauth2 flow names in OpenAPI 3 and their swagger 2.0 equivalents, in order of preference when a multi-flow scheme
+// has to be reduced to a single flow
+var oauthFlows = []struct {
+	openapi3 string
+	swagger2 string
+}{
+	{"authorizationCode", "accessCode"},
+	{"implicit", "implicit"},
+	{"password", "password"},
+	{"clientCredentials", "application"},
+}
+
+// BearerSecurity defines an http bearer security scheme; format, e.g. JWT, is a hint describing the token
+func BearerSecurity(format string) SecuritySchemeOption {
+	return func(securityScheme *SecurityScheme) {
+		securityScheme.Type = "http"
+		securityScheme.Scheme = "bearer"
+		securityScheme.BearerFormat = format
+	}
+}
+
+// OpenIDConnectSecurity defines an OpenID Connect security scheme discovered via the specified url e.g.
+// https://example.com/.well-known/openid-configuration
+func OpenIDConnectSecurity(url string) SecuritySchemeOption {
+	return func(securityScheme *SecurityScheme) {
+		securityScheme.Type = "openIdConnect"
+		securityScheme.OpenIDConnectURL = url
+	}
+}
+
+// OAuth2Flow adds a flow to an oauth2 security scheme; flow is one of the OpenAPI 3 flow names implicit, password,
+// clientCredentials or authorizationCode.  May be used more than once to declare several flows, which share the
+// scheme's scopes
+func OAuth2Flow(flow, authorizationURL, tokenURL, refreshURL string) SecuritySchemeOption {
+	known := false
+	for _, f := range oauthFlows {
+		known = known || f.openapi3 == flow
+	}
+	if !known {
+		panic(fmt.Errorf(`OAuth2Flow flow must be one of: "implicit", "password", "clientCredentials" or "authorizationCode"`))
+	}
+
+	return func(securityScheme *SecurityScheme) {
+		securityScheme.Type = "oauth2"
+		if securityScheme.Flows == nil {
+			securityScheme.Flows = map[string]OAuthFlow{}
+		}
+		securityScheme.Flows[flow] = OAuthFlow{
+			AuthorizationURL: authorizationURL,
+			TokenURL:         tokenURL,
+			RefreshURL:       refreshURL,
+		}
+		if securityScheme.Scopes == nil {
+			securityScheme.Scopes = map[string]string{}
+		}
+	}
+}
+
+type securityScheme SecurityScheme
+
+// jsonSecurityScheme is the swagger 2.0 form of a SecurityScheme; x-openapi3 preserves the original of a degraded
+// scheme so that it survives Load
+type jsonSecurityScheme struct {
+	securityScheme
+	OpenAPI3 *openapi3Scheme `json:"x-openapi3,omitempty"`
+}
+
+type openapi3Scheme struct {
+	Type             string               `json:"type"`
+	Scheme           string               `json:"scheme,omitempty"`
+	BearerFormat     string               `json:"bearerFormat,omitempty"`
+	OpenIDConnectURL string               `json:"openIdConnectUrl,omitempty"`
+	Name             string               `json:"name,omitempty"`
+	In               string               `json:"in,omitempty"`
+	Flows            map[string]OAuthFlow `json:"flows,omitempty"`
+}
+
+// Kind returns how a client presents credentials for the scheme: basic for basic and http basic schemes, bearer for
+// other http schemes and openIdConnect, whose tokens are obtained separately, or else the scheme's type i.e. apiKey or
+// oauth2
+func (s SecurityScheme) Kind() string {
+	switch {
+	case s.Type == "http" && strings.EqualFold(s.Scheme, "basic"):
+		return "basic"
+	case s.Type == "http", s.Type == "openIdConnect":
+		return "bearer"
+	}
+	return s.Type
+}
+
+// degraded reports whether the scheme can only be approximated in swagger 2.0; http basic maps to basic exactly
+func (s SecurityScheme) degraded() bool {
+	if s.Type == "http" {
+		return !strings.EqualFold(s.Scheme, "basic")
+	}
+	return s.Type == "openIdConnect" || s.In == "cookie" || len(s.Flows) > 0
+}
+
+// MarshalJSON renders the scheme as swagger 2.0, degrading schemes that only exist in OpenAPI 3
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	v, _ := s.swagger2()
+	j := jsonSecurityScheme{securityScheme: securityScheme(v)}
+	if s.degraded() {
+		j.OpenAPI3 = &openapi3Scheme{
+			Type:             s.Type,
+			Scheme:           s.Scheme,
+			BearerFormat:     s.BearerFormat,
+			OpenIDConnectURL: s.OpenIDConnectURL,
+			Name:             s.Name,
+			In:               s.In,
+			Flows:            s.Flows,
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON reads a swagger 2.0 scheme, restoring the original of a degraded scheme from its x-openapi3
+// extension
+func (s *SecurityScheme) UnmarshalJSON(data []byte) error {
+	j := jsonSecurityScheme{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*s = SecurityScheme(j.securityScheme)
+	if o := j.OpenAPI3; o != nil {
+		s.Type, s.Scheme, s.BearerFormat, s.OpenIDConnectURL = o.Type, o.Scheme, o.BearerFormat, o.OpenIDConnectURL
+		s.Name, s.In, s.Flows = o.Name, o.In, o.Flows
+		if len(s.Flows) > 0 {
+			s.Flow, s.AuthorizationURL, s.TokenURL = "", "", ""
+		}
+
+		// strip the note added by swagger2 from the description
+		original := *s
+		original.Description = ""
+		degraded, _ := original.swagger2()
+		if note := degraded.Description; note != "" {
+			if s.Description == note {
+				s.Description = ""
+			} else {
+				s.Description = strings.TrimSuffix(s.Description, "\n\n"+note)
+			}
+		}
+	}
+	return nil
+}
+
+// swagger2 returns the closest swagger 2.0 equivalent of the scheme along with a description of anything lost
+func (s SecurityScheme) swagger2() (SecurityScheme, []string) {
+	var warnings []string
+	v := SecurityScheme{
+		Type:             s.Type,
+		Description:      s.Description,
+		Name:             s.Name,
+		In:               s.In,
+		Flow:             s.Flow,
+		AuthorizationURL: s.AuthorizationURL,
+		TokenURL:         s.TokenURL,
+		Scopes:           s.Scopes,
+	}
+
+	note := func(text string) {
+		if v.Description != "" {
+			v.Description += "\n\n"
+		}
+		v.Description += text
+	}
+
+	switch {
+	case s.Type == "http" && strings.EqualFold(s.Scheme, "basic"):
+		v.Type = "basic"
+
+	case s.Type == "http":
+		v.Type, v.In, v.Name = "apiKey", "header", "Authorization"
+		text := fmt.Sprintf("%v authentication; send the Authorization header as: %v <token>", s.Scheme, authScheme(s.Scheme))
+		if s.BearerFormat != "" {
+			text += fmt.Sprintf(", where the token is a %v", s.BearerFormat)
+		}
+		note(text)
+		warnings = append(warnings, fmt.Sprintf("http %v authentication is not supported by swagger 2.0; documented as an Authorization header api key", s.Scheme))
+
+	case s.Type == "openIdConnect":
+		v.Type, v.In, v.Name = "apiKey", "header", "Authorization"
+		note(fmt.Sprintf("OpenID Connect, see %v; send the Authorization header as: Bearer <token>", s.OpenIDConnectURL))
+		warnings = append(warnings, "openIdConnect is not supported by swagger 2.0; documented as an Authorization header api key")
+
+	case s.Type == "apiKey" && s.In == "cookie":
+		v.In, v.Name = "header", "Cookie"
+		note(fmt.Sprintf("send the api key as the %v cookie", s.Name))
+		warnings = append(warnings, "cookie api keys are not supported by swagger 2.0; documented as the Cookie header")
+
+	case s.Type == "oauth2" && len(s.Flows) > 0:
+		v.Flow, v.AuthorizationURL, v.TokenURL = "", "", ""
+		var dropped []string
+		for _, f := range oauthFlows {
+			flow, ok := s.Flows[f.openapi3]
+			if !ok {
+				continue
+			}
+			if v.Flow != "" {
+				dropped = append(dropped, f.openapi3)
+				continue
+			}
+
+			v.Flow, v.AuthorizationURL, v.TokenURL = f.swagger2, flow.AuthorizationURL, flow.TokenURL
+			if flow.RefreshURL != "" {
+				warnings = append(warnings, "oauth2 refreshUrl is not supported by swagger 2.0; omitted")
+			}
+		}
+		if len(dropped) > 0 {
+			warnings = append(warnings, fmt.Sprintf("swagger 2.0 supports a single oauth2 flow; omitted %v", strings.Join(dropped, ", ")))
+		}
+	}
+
+	return v, warnings
+}
+
+// authScheme capitalizes the http authentication scheme as used in the Authorization header e.g. Bearer
+func authScheme(scheme string) string {
+	if scheme == "" {
+		return ""
+	}
+	return strings.ToUpper(scheme[:1]) + scheme[1:]
+}
+
+// Warnings describes the parts of the api that can't be expressed in swagger 2.0 and were degraded in its json, e.g.
+// bearer and OpenID Connect security schemes
+func (a *API) Warnings() []string {
+	names := make([]string, 0, len(a.SecurityDefinitions))
+	for name := range a.SecurityDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		_, w := a.SecurityDefinitions[name].swagger2()
+		for _, warning := range w {
+			warnings = append(warnings, fmt.Sprintf("securityDefinitions.%v: %v", name, warning))
+		}
+	}
+	return warnings
+}
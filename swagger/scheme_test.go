@@ -0,0 +1,108 @@
+package swagger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func marshalScheme(t *testing.T, opts ...SecuritySchemeOption) map[string]interface{} {
+	scheme := SecurityScheme{}
+	for _, opt := range opts {
+		opt(&scheme)
+	}
+
+	data, err := json.Marshal(scheme)
+	assert.Nil(t, err)
+
+	v := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(data, &v))
+	return v
+}
+
+func TestBearerSecurity(t *testing.T) {
+	v := marshalScheme(t, BearerSecurity("JWT"))
+	assert.Equal(t, "apiKey", v["type"])
+	assert.Equal(t, "header", v["in"])
+	assert.Equal(t, "Authorization", v["name"])
+	assert.Contains(t, v["description"], "Bearer <token>")
+	assert.Contains(t, v["description"], "JWT")
+	assert.NotContains(t, v, "scheme")
+}
+
+func TestHTTPBasicSecurity(t *testing.T) {
+	v := marshalScheme(t, func(s *SecurityScheme) { s.Type, s.Scheme = "http", "basic" })
+	assert.Equal(t, map[string]interface{}{"type": "basic"}, v)
+}
+
+func TestOpenIDConnectSecurity(t *testing.T) {
+	v := marshalScheme(t, OpenIDConnectSecurity("https://example.com/.well-known/openid-configuration"),
+		SecuritySchemeDescription("single sign on"))
+	assert.Equal(t, "apiKey", v["type"])
+	assert.Equal(t, "Authorization", v["name"])
+	assert.Contains(t, v["description"], "single sign on\n\nOpenID Connect, see https://example.com/")
+}
+
+func TestCookieSecurity(t *testing.T) {
+	v := marshalScheme(t, APIKeySecurity("session", "cookie"))
+	assert.Equal(t, "apiKey", v["type"])
+	assert.Equal(t, "header", v["in"])
+	assert.Equal(t, "Cookie", v["name"])
+	assert.Contains(t, v["description"], "session cookie")
+}
+
+func TestOAuth2Flow(t *testing.T) {
+	v := marshalScheme(t,
+		OAuth2Flow("clientCredentials", "", "https://example.com/token", ""),
+		OAuth2Flow("authorizationCode", "https://example.com/authorize", "https://example.com/token", "https://example.com/refresh"),
+		OAuth2Scope("read", "read data"),
+	)
+	assert.Equal(t, "oauth2", v["type"])
+	assert.Equal(t, "accessCode", v["flow"])
+	assert.Equal(t, "https://example.com/authorize", v["authorizationUrl"])
+	assert.Equal(t, map[string]interface{}{"read": "read data"}, v["scopes"])
+
+	assert.Panics(t, func() {
+		OAuth2Flow("accessCode", "", "", "")
+	})
+}
+
+func TestWarnings(t *testing.T) {
+	api := &API{
+		SecurityDefinitions: map[string]SecurityScheme{
+			"basic": {Type: "basic"},
+			"jwt":   {Type: "http", Scheme: "bearer"},
+			"oauth": {Type: "oauth2", Flows: map[string]OAuthFlow{
+				"authorizationCode": {TokenURL: "https://example.com/token", RefreshURL: "https://example.com/refresh"},
+				"implicit":          {AuthorizationURL: "https://example.com/authorize"},
+			}},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"securityDefinitions.jwt: http bearer authentication is not supported by swagger 2.0; documented as an Authorization header api key",
+		"securityDefinitions.oauth: oauth2 refreshUrl is not supported by swagger 2.0; omitted",
+		"securityDefinitions.oauth: swagger 2.0 supports a single oauth2 flow; omitted implicit",
+	}, api.Warnings())
+}
+
+func TestSecuritySchemeRoundTrip(t *testing.T) {
+	schemes := map[string]SecurityScheme{
+		"basic":   {Type: "basic", Description: "plain"},
+		"jwt":     {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "tokens"},
+		"oidc":    {Type: "openIdConnect", OpenIDConnectURL: "https://example.com/.well-known/openid-configuration"},
+		"session": {Type: "apiKey", Name: "session", In: "cookie"},
+		"oauth": {Type: "oauth2", Scopes: map[string]string{"read": "read data"}, Flows: map[string]OAuthFlow{
+			"authorizationCode": {AuthorizationURL: "https://example.com/authorize", TokenURL: "https://example.com/token"},
+			"clientCredentials": {TokenURL: "https://example.com/token", RefreshURL: "https://example.com/refresh"},
+		}},
+	}
+
+	data, err := json.Marshal(schemes)
+	assert.Nil(t, err)
+
+	restored := map[string]SecurityScheme{}
+	assert.Nil(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, schemes, restored)
+}
@@ -0,0 +1,386 @@
+// Package security enforces the security requirements declared by a swagger.API
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+var (
+	// ErrNoCredentials indicates the request carries no credentials for the scheme
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials indicates the credentials were present but rejected
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrInsufficientScope indicates the credentials are valid but lack a required scope
+	ErrInsufficientScope = errors.New("insufficient scope")
+	// ErrInternal indicates the credentials couldn't be verified e.g. because the user store is unavailable; such
+	// requests are answered with 500 rather than 401
+	ErrInternal = errors.New("internal error")
+)
+
+// Verifier authenticates a request against a security scheme; scopes are those required by the matched requirement.
+// Verify returns an identifier for the caller, the principal, or one of ErrNoCredentials, ErrInvalidCredentials,
+// ErrInsufficientScope or ErrInternal, which may be wrapped to add detail for the log
+type Verifier interface {
+	Verify(req *http.Request, scheme swagger.SecurityScheme, scopes []string) (interface{}, error)
+}
+
+// BasicFunc verifies basic authentication credentials
+type BasicFunc func(req *http.Request, username, password string) (interface{}, error)
+
+// Verify implements Verifier
+func (fn BasicFunc) Verify(req *http.Request, scheme swagger.SecurityScheme, scopes []string) (interface{}, error) {
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return fn(req, username, password)
+}
+
+// APIKeyFunc verifies an api key read from the header, query parameter or cookie named by the scheme
+type APIKeyFunc func(req *http.Request, key string) (interface{}, error)
+
+// Verify implements Verifier
+func (fn APIKeyFunc) Verify(req *http.Request, scheme swagger.SecurityScheme, scopes []string) (interface{}, error) {
+	var key string
+	switch scheme.In {
+	case "query":
+		key = req.URL.Query().Get(scheme.Name)
+	case "cookie":
+		if cookie, err := req.Cookie(scheme.Name); err == nil {
+			key = cookie.Value
+		}
+	default:
+		key = req.Header.Get(scheme.Name)
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	return fn(req, key)
+}
+
+// BearerFunc verifies a bearer token, e.g. an oauth2 access token, and returns the scopes it grants
+type BearerFunc func(req *http.Request, token string) (principal interface{}, scopes []string, err error)
+
+// Verify implements Verifier; every required scope must be granted by the token
+func (fn BearerFunc) Verify(req *http.Request, scheme swagger.SecurityScheme, scopes []string) (interface{}, error) {
+	token := bearerToken(req)
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	principal, granted, err := fn(req, token)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		if !contains(granted, scope) {
+			return nil, ErrInsufficientScope
+		}
+	}
+	return principal, nil
+}
+
+func bearerToken(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[7:])
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware enforces the effective security requirement of the endpoint matched by each request: the endpoint's
+// own requirement, none when the endpoint uses NoSecurity, or else the api's default.  It fails closed: requests that
+// don't match an endpoint are answered with 404, or 405 when the path is documented for other methods, unless their
+// path is Public.  HEAD requests are checked against the GET endpoint when no HEAD endpoint is documented
+type Middleware struct {
+	API *swagger.API
+
+	byName map[string]Verifier
+	byType map[string]Verifier
+	public []string
+	logger *log.Logger
+}
+
+// Option configures the Middleware
+type Option func(m *Middleware)
+
+// Scheme sets the verifier for the named security scheme; takes precedence over verifiers registered by type
+func Scheme(name string, v Verifier) Option {
+	return func(m *Middleware) {
+		m.byName[name] = v
+	}
+}
+
+// Public passes requests for the prefix, or a path below it, through unchecked when they don't match a documented
+// endpoint e.g. Public("/docs") passes /docs and /docs/swagger.json but not /docs-internal
+func Public(prefix string) Option {
+	return func(m *Middleware) {
+		m.public = append(m.public, prefix)
+	}
+}
+
+// Logger sets the logger for the reasons credentials were rejected, which aren't disclosed to the client; defaults to
+// the log package's standard logger
+func Logger(l *log.Logger) Option {
+	return func(m *Middleware) {
+		m.logger = l
+	}
+}
+
+// Basic sets the verifier for every basic and http basic security scheme
+func Basic(fn BasicFunc) Option {
+	return func(m *Middleware) {
+		m.byType["basic"] = fn
+	}
+}
+
+// APIKey sets the verifier for every apiKey security scheme
+func APIKey(fn APIKeyFunc) Option {
+	return func(m *Middleware) {
+		m.byType["apiKey"] = fn
+	}
+}
+
+// OAuth2 sets the verifier for every oauth2 security scheme; access tokens are read from the Authorization header
+func OAuth2(fn BearerFunc) Option {
+	return func(m *Middleware) {
+		m.byType["oauth2"] = fn
+	}
+}
+
+// Bearer sets the verifier for every http bearer and openIdConnect security scheme; tokens are read from the
+// Authorization header
+func Bearer(fn BearerFunc) Option {
+	return func(m *Middleware) {
+		m.byType["bearer"] = fn
+	}
+}
+
+// New returns a Middleware for the api
+func New(api *swagger.API, opts ...Option) *Middleware {
+	m := &Middleware{
+		API:    api,
+		byName: map[string]Verifier{},
+		byType: map[string]Verifier{},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Handler wraps next with security enforcement
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		api := m.API.Snapshot()
+		e := lookup(api, req.Method, req.URL.Path)
+		if e == nil {
+			m.unmatched(w, req, api, next)
+			return
+		}
+
+		principals, err := m.authenticate(req, api, api.EffectiveSecurity(e))
+		switch {
+		case err == nil:
+			if principals != nil {
+				req = req.WithContext(context.WithValue(req.Context(), principalsKey{}, principals))
+			}
+			next.ServeHTTP(w, req)
+
+		case errors.Is(err, ErrInternal):
+			m.logf("%v %v: %v", req.Method, req.URL.Path, err)
+			swagger.WriteProblem(w, http.StatusInternalServerError, "")
+
+		case errors.Is(err, ErrInsufficientScope):
+			m.logf("%v %v: %v", req.Method, req.URL.Path, err)
+			swagger.WriteProblem(w, http.StatusForbidden, "the credentials don't grant the required scope")
+
+		default:
+			m.logf("%v %v: %v", req.Method, req.URL.Path, err)
+			for _, challenge := range challenges(api, e) {
+				w.Header().Add("WWW-Authenticate", challenge)
+			}
+			swagger.WriteProblem(w, http.StatusUnauthorized, "valid credentials are required")
+		}
+	})
+}
+
+// methods are the http methods an endpoint may be documented for
+var methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// lookup finds the endpoint that serves the request; HEAD requests fall back to the GET endpoint
+func lookup(api *swagger.API, method, urlPath string) *swagger.Endpoint {
+	e, _ := api.Lookup(method, urlPath)
+	if e == nil && method == http.MethodHead {
+		e, _ = api.Lookup(http.MethodGet, urlPath)
+	}
+	return e
+}
+
+// unmatched answers a request that doesn't match a documented endpoint: public paths are passed to next, paths that
+// are documented for other methods get 405 and everything else 404
+func (m *Middleware) unmatched(w http.ResponseWriter, req *http.Request, api *swagger.API, next http.Handler) {
+	for _, prefix := range m.public {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/") {
+			next.ServeHTTP(w, req)
+			return
+		}
+	}
+
+	var allowed []string
+	for _, method := range methods {
+		if lookup(api, method, req.URL.Path) != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		swagger.WriteProblem(w, http.StatusMethodNotAllowed, "")
+		return
+	}
+	swagger.WriteProblem(w, http.StatusNotFound, "")
+}
+
+func (m *Middleware) logf(format string, args ...interface{}) {
+	if m.logger != nil {
+		m.logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// authenticate tries each alternative of the requirement in turn; all schemes of an alternative must verify.  Returns
+// the principals of the first satisfied alternative keyed by scheme name.  When no alternative is satisfied, the most
+// specific error wins: internal errors, then insufficient scope, then invalid, then missing credentials
+func (m *Middleware) authenticate(req *http.Request, api *swagger.API, requirement *swagger.SecurityRequirement) (map[string]interface{}, error) {
+	if requirement == nil || len(requirement.Requirements) == 0 {
+		return nil, nil
+	}
+
+	failure := ErrNoCredentials
+	for _, alternative := range requirement.Requirements {
+		principals, err := m.verify(req, api, alternative)
+		if err == nil {
+			return principals, nil
+		}
+		if rank(err) > rank(failure) {
+			failure = err
+		}
+	}
+
+	return nil, failure
+}
+
+func (m *Middleware) verify(req *http.Request, api *swagger.API, alternative map[string][]string) (map[string]interface{}, error) {
+	principals := map[string]interface{}{}
+	for _, name := range sortedKeys(alternative) {
+		scheme, ok := api.SecurityDefinitions[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown security scheme, %v", ErrInvalidCredentials, name)
+		}
+
+		v := m.verifier(name, scheme)
+		if v == nil {
+			return nil, fmt.Errorf("%w: no verifier for security scheme, %v", ErrInvalidCredentials, name)
+		}
+
+		principal, err := v.Verify(req, scheme, alternative[name])
+		if err != nil {
+			return nil, err
+		}
+		principals[name] = principal
+	}
+	return principals, nil
+}
+
+func (m *Middleware) verifier(name string, scheme swagger.SecurityScheme) Verifier {
+	if v, ok := m.byName[name]; ok {
+		return v
+	}
+	return m.byType[scheme.Kind()]
+}
+
+// challenges returns the WWW-Authenticate challenges of the schemes that could satisfy the endpoint
+func challenges(api *swagger.API, e *swagger.Endpoint) []string {
+	requirement := api.EffectiveSecurity(e)
+	if requirement == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var challenges []string
+	for _, alternative := range requirement.Requirements {
+		for _, name := range sortedKeys(alternative) {
+			var challenge string
+			scheme := api.SecurityDefinitions[name]
+			switch {
+			case scheme.Type == "basic", scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+				challenge = `Basic realm="` + name + `"`
+			case scheme.Type == "oauth2", scheme.Type == "openIdConnect", scheme.Type == "http":
+				challenge = "Bearer"
+			}
+			if challenge != "" && !seen[challenge] {
+				seen[challenge] = true
+				challenges = append(challenges, challenge)
+			}
+		}
+	}
+	return challenges
+}
+
+func rank(err error) int {
+	switch {
+	case errors.Is(err, ErrInternal):
+		return 3
+	case errors.Is(err, ErrInsufficientScope):
+		return 2
+	case errors.Is(err, ErrNoCredentials):
+		return 0
+	default:
+		return 1
+	}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type principalsKey struct{}
+
+// Principals returns the principals of the authenticated request keyed by security scheme name; nil when the endpoint
+// required no security
+func Principals(ctx context.Context) map[string]interface{} {
+	v, _ := ctx.Value(principalsKey{}).(map[string]interface{})
+	return v
+}
+
+// Principal returns the principal authenticated by the named security scheme, if any
+func Principal(ctx context.Context, scheme string) interface{} {
+	return Principals(ctx)[scheme]
+}
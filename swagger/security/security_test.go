@@ -0,0 +1,241 @@
+package security
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+	"github.com/threeq/docs/swagger/endpoint"
+)
+
+func testAPI() *swagger.API {
+	api := &swagger.API{
+		BasePath: "/api",
+		SecurityDefinitions: map[string]swagger.SecurityScheme{
+			"basic":   {Type: "basic"},
+			"api_key": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			"tenant":  {Type: "apiKey", Name: "tenant", In: "query"},
+			"oauth":   {Type: "oauth2", Flow: "accessCode"},
+		},
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{{"basic": {}}}},
+	}
+
+	api.AddEndpoint(endpoint.Get("/pets", "list pets"))
+	api.AddEndpoint(endpoint.Get("/health", "health check", endpoint.NoSecurity()))
+	api.AddEndpoint(endpoint.Post("/pets", "create pet",
+		endpoint.Security("oauth", "write"),
+		endpoint.Security("api_key"),
+	))
+	api.AddEndpoint(endpoint.Delete("/pets/{id}", "delete pet",
+		endpoint.Path("id", "integer", "pet id", true),
+	))
+	api.Paths["/pets/{id}"].Delete.Security = &swagger.SecurityRequirement{
+		Requirements: []map[string][]string{{"api_key": {}, "tenant": {}}},
+	}
+
+	return api
+}
+
+func testHandler() http.Handler {
+	m := New(testAPI(),
+		Basic(func(req *http.Request, username, password string) (interface{}, error) {
+			if username == "joe" && password == "secret" {
+				return username, nil
+			}
+			return nil, ErrInvalidCredentials
+		}),
+		APIKey(func(req *http.Request, key string) (interface{}, error) {
+			return "key:" + key, nil
+		}),
+		OAuth2(func(req *http.Request, token string) (interface{}, []string, error) {
+			if token != "valid" {
+				return nil, nil, ErrInvalidCredentials
+			}
+			return "oauth-user", []string{"read"}, nil
+		}),
+	)
+
+	return m.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for name, principal := range Principals(req.Context()) {
+			w.Header().Add("X-Principal", name+"="+principal.(string))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func serve(h http.Handler, method, target string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestDefaultRequirement(t *testing.T) {
+	h := testHandler()
+
+	w := serve(h, http.MethodGet, "/api/pets")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="basic"`, w.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, swagger.ProblemContentType, w.Header().Get("Content-Type"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+	req.SetBasicAuth("joe", "wrong")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req.SetBasicAuth("joe", "secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "basic=joe", w.Header().Get("X-Principal"))
+}
+
+func TestNoSecurityAndUnmatched(t *testing.T) {
+	h := testHandler()
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/health").Code)
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/api/unknown").Code)
+
+	w := serve(h, http.MethodPut, "/api/pets")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, HEAD, POST", w.Header().Get("Allow"))
+
+	// HEAD is checked against the GET endpoint
+	assert.Equal(t, http.StatusUnauthorized, serve(h, http.MethodHead, "/api/pets").Code)
+
+	public := New(testAPI(), Public("/docs")).Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	assert.Equal(t, http.StatusOK, serve(public, http.MethodGet, "/docs/swagger.json").Code)
+	assert.Equal(t, http.StatusOK, serve(public, http.MethodGet, "/docs").Code)
+	assert.Equal(t, http.StatusNotFound, serve(public, http.MethodGet, "/docs-internal").Code)
+	assert.Equal(t, http.StatusNotFound, serve(public, http.MethodGet, "/api/unknown").Code)
+}
+
+func TestInternalError(t *testing.T) {
+	logs := &bytes.Buffer{}
+	m := New(testAPI(),
+		Logger(log.New(logs, "", 0)),
+		Basic(func(req *http.Request, username, password string) (interface{}, error) {
+			return nil, fmt.Errorf("%w: user store unavailable", ErrInternal)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+	req.SetBasicAuth("joe", "secret")
+	w := httptest.NewRecorder()
+	m.Handler(http.NotFoundHandler()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+	assert.NotContains(t, w.Body.String(), "unavailable")
+	assert.Equal(t, "GET /api/pets: internal error: user store unavailable\n", logs.String())
+}
+
+func TestProblemDetail(t *testing.T) {
+	logs := &bytes.Buffer{}
+	m := New(testAPI(),
+		Logger(log.New(logs, "", 0)),
+		Basic(func(req *http.Request, username, password string) (interface{}, error) {
+			return nil, fmt.Errorf("%w: user %v is locked", ErrInvalidCredentials, username)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+	req.SetBasicAuth("joe", "secret")
+	w := httptest.NewRecorder()
+	m.Handler(http.NotFoundHandler()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotContains(t, w.Body.String(), "locked")
+	assert.Contains(t, w.Body.String(), "valid credentials are required")
+	assert.Equal(t, "GET /api/pets: invalid credentials: user joe is locked\n", logs.String())
+}
+
+func TestAlternatives(t *testing.T) {
+	h := testHandler()
+
+	// the token is valid but lacks the write scope, and there is no api key
+	w := serve(h, http.MethodPost, "/api/pets", "Authorization", "Bearer valid")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(h, http.MethodPost, "/api/pets", "Authorization", "Bearer invalid")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = serve(h, http.MethodPost, "/api/pets", "Authorization", "Bearer valid", "X-API-Key", "abc")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "api_key=key:abc", w.Header().Get("X-Principal"))
+}
+
+func TestAllSchemesRequired(t *testing.T) {
+	h := testHandler()
+
+	w := serve(h, http.MethodDelete, "/api/pets/1", "X-API-Key", "abc")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve(h, http.MethodDelete, "/api/pets/1?tenant=acme", "X-API-Key", "abc")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.ElementsMatch(t, []string{"api_key=key:abc", "tenant=key:acme"}, w.Header()["X-Principal"])
+}
+
+func TestSchemeOverride(t *testing.T) {
+	m := New(testAPI(),
+		Scheme("basic", BasicFunc(func(req *http.Request, username, password string) (interface{}, error) {
+			return "override", nil
+		})),
+	)
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "override", Principal(req.Context(), "basic"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+	req.SetBasicAuth("anyone", "anything")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBearerAndCookie(t *testing.T) {
+	api := &swagger.API{
+		BasePath: "/api",
+		SecurityDefinitions: map[string]swagger.SecurityScheme{
+			"jwt":     {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			"session": {Type: "apiKey", Name: "session", In: "cookie"},
+		},
+		Security: &swagger.SecurityRequirement{Requirements: []map[string][]string{{"jwt": {}}, {"session": {}}}},
+	}
+	api.AddEndpoint(endpoint.Get("/pets", "list pets"))
+
+	m := New(api,
+		Bearer(func(req *http.Request, token string) (interface{}, []string, error) {
+			return "token:" + token, nil, nil
+		}),
+		APIKey(func(req *http.Request, key string) (interface{}, error) {
+			return "cookie:" + key, nil
+		}),
+	)
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for name, principal := range Principals(req.Context()) {
+			w.Header().Add("X-Principal", name+"="+principal.(string))
+		}
+	}))
+
+	w := serve(h, http.MethodGet, "/api/pets")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = serve(h, http.MethodGet, "/api/pets", "Authorization", "Bearer abc")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "jwt=token:abc", w.Header().Get("X-Principal"))
+
+	w = serve(h, http.MethodGet, "/api/pets", "Cookie", "session=xyz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "session=cookie:xyz", w.Header().Get("X-Principal"))
+}
@@ -11,4 +11,7 @@ type Tag struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Docs        *Docs  `json:"externalDocs,omitempty"`
+
+	// Extensions holds vendor extensions, x-*, inlined into the json
+	Extensions map[string]interface{} `json:"-"`
 }
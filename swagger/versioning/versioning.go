@@ -0,0 +1,322 @@
+// Package versioning serves several live versions of a swagger.API side by side; each version has its own document
+// and its own endpoints, selected per request by a Strategy
+package versioning
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/threeq/docs/swagger"
+)
+
+// Strategy determines how clients select a version
+type Strategy interface {
+	// Version returns the version requested by req, or "" if the request doesn't specify one; basePath is that of the
+	// unversioned api and versions are those the router serves
+	Version(req *http.Request, basePath string, versions []string) string
+
+	// Configure adapts the api of a version e.g. by prefixing its basePath
+	Configure(api *swagger.API, version string)
+
+	// Endpoint adapts a copy of an endpoint before it is added to the api of a version e.g. by declaring a version
+	// header
+	Endpoint(e *swagger.Endpoint, version string)
+}
+
+type urlPrefix struct{}
+
+// URLPrefix selects the version by the path segment directly following the basePath e.g. /api/v2/pets; other
+// segments that happen to equal a version, e.g. the id in /api/pets/v2, are ignored
+func URLPrefix() Strategy {
+	return urlPrefix{}
+}
+
+func (urlPrefix) Version(req *http.Request, basePath string, versions []string) string {
+	rest, ok := trimBasePath(req.URL.Path, basePath)
+	if !ok {
+		return ""
+	}
+	return firstSegment(rest, versions)
+}
+
+// trimBasePath returns the part of the request path following the basePath; ok is false if the path isn't under it
+func trimBasePath(urlPath, basePath string) (string, bool) {
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath == "" {
+		return urlPath, true
+	}
+	if urlPath != basePath && !strings.HasPrefix(urlPath, basePath+"/") {
+		return "", false
+	}
+	return urlPath[len(basePath):], true
+}
+
+// firstSegment returns the first segment of the path if it's one of the versions, otherwise ""
+func firstSegment(urlPath string, versions []string) string {
+	segment := strings.SplitN(strings.TrimPrefix(urlPath, "/"), "/", 2)[0]
+	if contains(versions, segment) {
+		return segment
+	}
+	return ""
+}
+
+func (urlPrefix) Configure(api *swagger.API, version string) {
+	api.BasePath = path.Join("/", api.BasePath, version)
+}
+
+func (urlPrefix) Endpoint(e *swagger.Endpoint, version string) {}
+
+type header struct {
+	name string
+}
+
+// Header selects the version by the value of the named request header e.g. Header("API-Version")
+func Header(name string) Strategy {
+	return header{name: name}
+}
+
+func (h header) Version(req *http.Request, basePath string, versions []string) string {
+	return strings.TrimSpace(req.Header.Get(h.name))
+}
+
+func (h header) Configure(api *swagger.API, version string) {}
+
+func (h header) Endpoint(e *swagger.Endpoint, version string) {
+	e.Parameters = append(e.Parameters, swagger.Parameter{
+		In:          "header",
+		Name:        h.name,
+		Type:        "string",
+		Description: "the api version, " + version,
+	})
+}
+
+type mediaType struct {
+	format string
+}
+
+// MediaType selects the version by the media types of the Accept and Content-Type headers; format contains a single
+// %v for the version e.g. MediaType("application/vnd.example.%v+json").  Documents declare the versioned media type in
+// place of application/json
+func MediaType(format string) Strategy {
+	if strings.Count(format, "%v") != 1 {
+		panic(fmt.Errorf("MediaType format must contain a single %%v; got %v", format))
+	}
+	return mediaType{format: format}
+}
+
+func (m mediaType) Version(req *http.Request, basePath string, versions []string) string {
+	parts := strings.SplitN(strings.ToLower(m.format), "%v", 2)
+	prefix, suffix := parts[0], parts[1]
+
+	values := strings.Split(req.Header.Get("Accept"), ",")
+	values = append(values, req.Header.Get("Content-Type"))
+	for _, value := range values {
+		t, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil || len(t) <= len(prefix)+len(suffix) {
+			continue
+		}
+		if strings.HasPrefix(t, prefix) && strings.HasSuffix(t, suffix) {
+			return t[len(prefix) : len(t)-len(suffix)]
+		}
+	}
+	return ""
+}
+
+func (m mediaType) Configure(api *swagger.API, version string) {}
+
+func (m mediaType) Endpoint(e *swagger.Endpoint, version string) {
+	versioned := fmt.Sprintf(m.format, version)
+	replace := func(mediaTypes []string) []string {
+		v := make([]string, 0, len(mediaTypes))
+		for _, t := range mediaTypes {
+			if t == "application/json" {
+				t = versioned
+			}
+			if !contains(v, t) {
+				v = append(v, t)
+			}
+		}
+		return v
+	}
+
+	e.Produces = replace(e.Produces)
+	e.Consumes = replace(e.Consumes)
+}
+
+// Router holds one swagger.API per version and dispatches each request to the endpoint of the version it selects
+type Router struct {
+	Strategy Strategy
+
+	// Default is the version of requests that don't specify one; defaults to the latest version
+	Default string
+
+	basePath string
+	versions []string
+	apis     map[string]*swagger.API
+}
+
+// New returns a Router for the versions, oldest first, of the base api.  Each version starts as a copy of base's info,
+// security, tags and other settings along with the endpoints available in that version; panics if no versions are
+// specified
+func New(base *swagger.API, strategy Strategy, versions ...string) *Router {
+	if len(versions) == 0 {
+		panic(fmt.Errorf("versioning.New requires at least one version"))
+	}
+
+	r := &Router{
+		Strategy: strategy,
+		Default:  versions[len(versions)-1],
+		basePath: path.Join("/", base.BasePath),
+		versions: versions,
+		apis:     map[string]*swagger.API{},
+	}
+
+	for _, version := range versions {
+		api := base.Snapshot()
+		api.Paths = nil
+		api.Definitions = nil
+		api.Info.Version = version
+		strategy.Configure(api, version)
+		r.apis[version] = api
+	}
+
+	base.Walk(func(_ string, e *swagger.Endpoint) {
+		r.AddEndpoint(e)
+	})
+
+	return r
+}
+
+// Versions returns the versions served by the router, oldest first
+func (r *Router) Versions() []string {
+	return append([]string(nil), r.versions...)
+}
+
+// API returns the api of the version, or nil if the version isn't served
+func (r *Router) API(version string) *swagger.API {
+	return r.apis[version]
+}
+
+// AddEndpoint adds a copy of the endpoint to the api of every version within its x-since and x-until range, both
+// inclusive; panics if the range names an unknown version or ends before it starts
+func (r *Router) AddEndpoint(e *swagger.Endpoint) {
+	since, until := 0, len(r.versions)-1
+	if v, ok := e.Extensions[swagger.SinceExtension].(string); ok {
+		since = r.index(e, v)
+	}
+	if v, ok := e.Extensions[swagger.UntilExtension].(string); ok {
+		until = r.index(e, v)
+	}
+	if since > until {
+		panic(fmt.Errorf("versioning: %v %v is available since %v, which is later than until %v", e.Method, e.Path, r.versions[since], r.versions[until]))
+	}
+
+	for _, version := range r.versions[since : until+1] {
+		c := copyEndpoint(e)
+		r.Strategy.Endpoint(c, version)
+		r.apis[version].AddEndpoint(c)
+	}
+}
+
+// RemoveEndpoint removes the endpoint with the specified method and path from every version; returns false if no
+// version had it
+func (r *Router) RemoveEndpoint(method, path string) bool {
+	removed := false
+	for _, version := range r.versions {
+		removed = r.apis[version].RemoveEndpoint(method, path) || removed
+	}
+	return removed
+}
+
+func (r *Router) index(e *swagger.Endpoint, version string) int {
+	for i, v := range r.versions {
+		if v == version {
+			return i
+		}
+	}
+	panic(fmt.Errorf("versioning: %v %v names an unknown version, %v", e.Method, e.Path, version))
+}
+
+// copyEndpoint returns a copy of the endpoint whose parameters, media types and responses can be modified without
+// affecting the original
+func copyEndpoint(e *swagger.Endpoint) *swagger.Endpoint {
+	c := *e
+	c.Parameters = append([]swagger.Parameter(nil), e.Parameters...)
+	c.Produces = append([]string(nil), e.Produces...)
+	c.Consumes = append([]string(nil), e.Consumes...)
+	if e.Responses != nil {
+		c.Responses = make(map[string]swagger.Response, len(e.Responses))
+		for k, v := range e.Responses {
+			c.Responses[k] = v
+		}
+	}
+	return &c
+}
+
+// version returns the version selected by the request, the Default when it doesn't select one
+func (r *Router) version(req *http.Request) string {
+	if v := r.Strategy.Version(req, r.basePath, r.versions); v != "" {
+		return v
+	}
+	return r.Default
+}
+
+// ServeHTTP dispatches the request to the handler of the matching endpoint in the selected version; requests for
+// versions that aren't served, or for endpoints the version doesn't have, are answered with 404
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	version := r.version(req)
+	api := r.API(version)
+	if api == nil {
+		swagger.WriteProblem(w, http.StatusNotFound, "unknown api version, "+version)
+		return
+	}
+
+	snapshot := api.Snapshot()
+	e, _ := snapshot.Lookup(req.Method, req.URL.Path)
+	if e == nil {
+		swagger.WriteProblem(w, http.StatusNotFound, fmt.Sprintf("no endpoint declared for %v %v in version %v", req.Method, req.URL.Path, version))
+		return
+	}
+
+	snapshot.Paths[e.Path].ServeHTTP(w, req)
+}
+
+// DocsHandler serves the document of the version named by the path segment following the basePath, or by the first
+// segment of paths outside the basePath e.g. /v2/swagger.json, or else of the Default version; options are those of
+// swagger.API.Handler
+func (r *Router) DocsHandler(enableCors bool, options ...swagger.HandlerOption) http.Handler {
+	handlers := map[string]http.HandlerFunc{}
+	for version, api := range r.apis {
+		handlers[version] = api.Handler(enableCors, options...)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rest, ok := trimBasePath(req.URL.Path, r.basePath)
+		if !ok {
+			rest = req.URL.Path
+		}
+		version := firstSegment(rest, r.versions)
+		if version == "" {
+			version = r.Default
+		}
+
+		h, ok := handlers[version]
+		if !ok {
+			swagger.WriteProblem(w, http.StatusNotFound, "unknown api version, "+version)
+			return
+		}
+		h(w, req)
+	})
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package versioning
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threeq/docs/swagger"
+)
+
+func handler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, body)
+	}
+}
+
+func testAPI() *swagger.API {
+	api := &swagger.API{
+		BasePath: "/api",
+		Info:     swagger.Info{Title: "Petstore"},
+	}
+
+	api.AddEndpoint(&swagger.Endpoint{
+		Method:     "GET",
+		Path:       "/pets",
+		Produces:   []string{"application/json"},
+		Handler:    handler("pets v1"),
+		Extensions: map[string]interface{}{swagger.UntilExtension: "v1"},
+	})
+	api.AddEndpoint(&swagger.Endpoint{
+		Method:     "GET",
+		Path:       "/owners",
+		Produces:   []string{"application/json"},
+		Handler:    handler("owners"),
+		Extensions: map[string]interface{}{swagger.SinceExtension: "v2"},
+	})
+	api.AddEndpoint(&swagger.Endpoint{
+		Method:   "GET",
+		Path:     "/health",
+		Produces: []string{"application/json"},
+		Handler:  handler("ok"),
+	})
+
+	return api
+}
+
+func serve(h http.Handler, target string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestURLPrefix(t *testing.T) {
+	r := New(testAPI(), URLPrefix(), "v1", "v2")
+
+	// the v2 /pets differs from v1
+	r.AddEndpoint(&swagger.Endpoint{
+		Method:     "GET",
+		Path:       "/pets",
+		Handler:    handler("pets v2"),
+		Extensions: map[string]interface{}{swagger.SinceExtension: "v2"},
+	})
+
+	assert.Equal(t, []string{"v1", "v2"}, r.Versions())
+	assert.Equal(t, "/api/v1", r.API("v1").BasePath)
+	assert.Equal(t, "v2", r.API("v2").Info.Version)
+	assert.Equal(t, "Petstore", r.API("v2").Info.Title)
+
+	assert.Equal(t, "pets v1", serve(r, "/api/v1/pets").Body.String())
+	assert.Equal(t, "pets v2", serve(r, "/api/v2/pets").Body.String())
+	assert.Equal(t, "ok", serve(r, "/api/v1/health").Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/v1/owners").Code)
+	assert.Equal(t, "owners", serve(r, "/api/v2/owners").Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/v3/owners").Code)
+
+	// only the segment following the basePath selects the version
+	r.AddEndpoint(&swagger.Endpoint{Method: "GET", Path: "/pets/{id}", Handler: handler("pet")})
+	assert.Equal(t, "pet", serve(r, "/api/v2/pets/v1").Body.String())
+	versions := r.Versions()
+	assert.Equal(t, "", URLPrefix().Version(httptest.NewRequest("GET", "/api/pets/v2", nil), "/api", versions))
+	assert.Equal(t, "", URLPrefix().Version(httptest.NewRequest("GET", "/v2/pets", nil), "/api", versions))
+	assert.Equal(t, "v2", URLPrefix().Version(httptest.NewRequest("GET", "/v2/pets", nil), "/", versions))
+
+	assert.True(t, r.RemoveEndpoint("GET", "/health"))
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/v2/health").Code)
+	assert.False(t, r.RemoveEndpoint("GET", "/health"))
+}
+
+func TestHeader(t *testing.T) {
+	r := New(testAPI(), Header("API-Version"), "v1", "v2")
+
+	assert.Equal(t, "pets v1", serve(r, "/api/pets", "API-Version", "v1").Body.String())
+	assert.Equal(t, "owners", serve(r, "/api/owners", "API-Version", "v2").Body.String())
+
+	// requests without a version are served the latest
+	assert.Equal(t, "owners", serve(r, "/api/owners").Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/pets").Code)
+
+	r.Default = "v1"
+	assert.Equal(t, "pets v1", serve(r, "/api/pets").Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/pets", "API-Version", "v9").Code)
+
+	e := r.API("v1").Paths["/pets"].Get
+	assert.Equal(t, swagger.Parameter{In: "header", Name: "API-Version", Type: "string", Description: "the api version, v1"}, e.Parameters[0])
+}
+
+func TestMediaType(t *testing.T) {
+	r := New(testAPI(), MediaType("application/vnd.petstore.%v+json"), "v1", "v2")
+
+	assert.Equal(t, "pets v1", serve(r, "/api/pets", "Accept", "text/html, application/vnd.petstore.v1+json; q=0.9").Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/pets", "Accept", "application/vnd.petstore.v2+json").Code)
+	assert.Equal(t, http.StatusNotFound, serve(r, "/api/pets", "Accept", "application/vnd.petstore.v3+json").Code)
+
+	assert.Equal(t, []string{"application/vnd.petstore.v2+json"}, r.API("v2").Paths["/health"].Get.Produces)
+
+	assert.Panics(t, func() {
+		MediaType("application/vnd.petstore+json")
+	})
+}
+
+func TestDocsHandler(t *testing.T) {
+	r := New(testAPI(), URLPrefix(), "v1", "v2")
+	h := r.DocsHandler(false)
+
+	paths := func(target string) map[string]interface{} {
+		w := serve(h, target)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		v := struct {
+			BasePath string                 `json:"basePath"`
+			Paths    map[string]interface{} `json:"paths"`
+		}{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &v))
+		return v.Paths
+	}
+
+	assert.Contains(t, paths("/v1/swagger.json"), "/pets")
+	assert.NotContains(t, paths("/v1/swagger.json"), "/owners")
+	assert.Contains(t, paths("/v2/swagger.json"), "/owners")
+	assert.Contains(t, paths("/swagger.json"), "/owners")
+	assert.NotContains(t, paths("/api/v1/swagger.json"), "/owners")
+	assert.Contains(t, paths("/api/docs/v1/swagger.json"), "/owners")
+
+	r.Default = "v1"
+	assert.NotContains(t, paths("/swagger.json"), "/owners")
+	r.Default = "v9"
+	assert.Equal(t, http.StatusNotFound, serve(h, "/swagger.json").Code)
+	r.Default = "v2"
+
+	// endpoints added later appear in the served documents
+	r.AddEndpoint(&swagger.Endpoint{Method: "GET", Path: "/stores"})
+	assert.Contains(t, paths("/v1/swagger.json"), "/stores")
+
+	assert.Panics(t, func() {
+		r.AddEndpoint(&swagger.Endpoint{Method: "GET", Path: "/x", Extensions: map[string]interface{}{swagger.SinceExtension: "v9"}})
+	})
+	assert.PanicsWithError(t, "versioning: GET /x is available since v2, which is later than until v1", func() {
+		r.AddEndpoint(&swagger.Endpoint{Method: "GET", Path: "/x", Extensions: map[string]interface{}{swagger.SinceExtension: "v2", swagger.UntilExtension: "v1"}})
+	})
+}